@@ -0,0 +1,80 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"fmt"
+	"strings"
+)
+
+// artworkSizeSegment is the size segment of the 100px artwork
+// URL, e.g. ".../source/100x100bb.jpg".
+const artworkSizeSegment = "100x100"
+
+// ArtworkURL returns the URL of the result's artwork at size×size
+// pixels. The URLs sent by the API are used for the 30, 60 and 100px
+// sizes, and any other size is derived from the 100px artwork URL.
+// It returns "" if the result has no artwork URL for size.
+func (r *Result) ArtworkURL(size int) string {
+	switch {
+	case size == 30 && r.ArtworkURL30Px != "":
+		return r.ArtworkURL30Px
+	case size == 60 && r.ArtworkURL60Px != "":
+		return r.ArtworkURL60Px
+	case size == 100 && r.ArtworkURL100Px != "":
+		return r.ArtworkURL100Px
+	}
+	return resizeArtworkURL(r.ArtworkURL100Px, size)
+}
+
+func resizeArtworkURL(url100 string, size int) string {
+	i := strings.LastIndex(url100, artworkSizeSegment)
+	if i < 0 || size <= 0 {
+		return ""
+	}
+	return url100[:i] + fmt.Sprintf("%dx%d", size, size) + url100[i+len(artworkSizeSegment):]
+}
+
+// normalizeArtwork derives any missing 30px and 60px artwork URLs.
+func (r *Result) normalizeArtwork() {
+	if r.ArtworkURL30Px == "" {
+		r.ArtworkURL30Px = r.ArtworkURL(30)
+	}
+	if r.ArtworkURL60Px == "" {
+		r.ArtworkURL60Px = r.ArtworkURL(60)
+	}
+}
+
+// derivedArtworkSizes are the sizes that ArtworkURLs derives from
+// the 100px artwork URL, on top of those sent by the API.
+var derivedArtworkSizes = []int{200, 400, 600}
+
+// ArtworkURLs returns the result's artwork URLs keyed by size in
+// pixels: the 30, 60 and 100px URLs sent by the API, as available,
+// and the 200, 400 and 600px URLs derived from the 100px one.
+func (r *Result) ArtworkURLs() map[int]string {
+	urls := make(map[int]string)
+	for size, artworkURL := range map[int]string{30: r.ArtworkURL30Px, 60: r.ArtworkURL60Px, 100: r.ArtworkURL100Px} {
+		if artworkURL != "" {
+			urls[size] = artworkURL
+		}
+	}
+	for _, size := range derivedArtworkSizes {
+		if artworkURL := resizeArtworkURL(r.ArtworkURL100Px, size); artworkURL != "" {
+			urls[size] = artworkURL
+		}
+	}
+	return urls
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestWithNormalizeArtwork(t *testing.T) {
+	const base = "https://is1-ssl.mzstatic.com/image/thumb/Music/v4/0a/1b/source/"
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"resultCount": 2, "results": [
+			{"trackId": 1, "artworkUrl100": "%[1]s100x100bb.jpg"},
+			{"trackId": 2, "artworkUrl100": "%[1]s100x100bb.jpg", "artworkUrl60": "%[1]s60x60.jpg"}
+		]}`, base)
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	client := NewClient(WithNormalizeArtwork())
+	client.searchURL = cst.URL
+	sres, err := client.Search(ctx, &Search{Term: "art"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	first, second := sres.Results[0], sres.Results[1]
+	if g, w := first.ArtworkURL30Px, base+"30x30bb.jpg"; g != w {
+		t.Errorf("ArtworkURL30Px=%q want %q", g, w)
+	}
+	if g, w := first.ArtworkURL60Px, base+"60x60bb.jpg"; g != w {
+		t.Errorf("ArtworkURL60Px=%q want %q", g, w)
+	}
+	// URLs sent by the API are left as they are.
+	if g, w := second.ArtworkURL60Px, base+"60x60.jpg"; g != w {
+		t.Errorf("ArtworkURL60Px=%q want %q", g, w)
+	}
+
+	// Without the option, missing artwork URLs stay empty.
+	plain := &Client{searchURL: cst.URL}
+	sres, err = plain.Search(ctx, &Search{Term: "art"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if g := sres.Results[0].ArtworkURL30Px; g != "" {
+		t.Errorf("ArtworkURL30Px=%q want it empty", g)
+	}
+}
+
+func TestResultArtworkURLs(t *testing.T) {
+	const base = "https://is1-ssl.mzstatic.com/image/thumb/Music/v4/0a/1b/source/"
+	res := &Result{
+		ArtworkURL60Px:  base + "60x60bb.jpg",
+		ArtworkURL100Px: base + "100x100bb.jpg",
+	}
+	want := map[int]string{
+		60:  base + "60x60bb.jpg",
+		100: base + "100x100bb.jpg",
+		200: base + "200x200bb.jpg",
+		400: base + "400x400bb.jpg",
+		600: base + "600x600bb.jpg",
+	}
+	if g := res.ArtworkURLs(); !reflect.DeepEqual(g, want) {
+		t.Errorf("ArtworkURLs=%v want %v", g, want)
+	}
+
+	if g := new(Result).ArtworkURLs(); len(g) != 0 {
+		t.Errorf("without artwork: ArtworkURLs=%v want it empty", g)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+// SearchBuilder builds a Search one parameter at a time, e.g.
+//
+//	s, err := itunes.NewSearch("jack johnson").Media(itunes.MediaMusic).Limit(25).Build()
+//
+// Each of its methods returns the builder itself so that calls chain.
+type SearchBuilder struct {
+	s Search
+}
+
+// NewSearch starts building a Search for term.
+func NewSearch(term string) *SearchBuilder {
+	return &SearchBuilder{s: Search{Term: term}}
+}
+
+func (b *SearchBuilder) Country(country Country) *SearchBuilder {
+	b.s.Country = country
+	return b
+}
+
+func (b *SearchBuilder) Media(media Media) *SearchBuilder {
+	b.s.Media = media
+	return b
+}
+
+// Entity adds entity to the entities to search for.
+func (b *SearchBuilder) Entity(entity Entity) *SearchBuilder {
+	if b.s.Entity == "" {
+		b.s.Entity = entity
+	} else {
+		b.s.Entities = append(b.s.Entities, entity)
+	}
+	return b
+}
+
+func (b *SearchBuilder) Attribute(attribute Attribute) *SearchBuilder {
+	b.s.Attribute = attribute
+	return b
+}
+
+func (b *SearchBuilder) Language(language Language) *SearchBuilder {
+	b.s.Language = language
+	return b
+}
+
+func (b *SearchBuilder) Limit(limit uint) *SearchBuilder {
+	b.s.Limit = limit
+	return b
+}
+
+func (b *SearchBuilder) Offset(offset uint) *SearchBuilder {
+	b.s.Offset = offset
+	return b
+}
+
+func (b *SearchBuilder) Version(version Version) *SearchBuilder {
+	b.s.Version = version
+	return b
+}
+
+// Explicit sets whether to include explicit content.
+func (b *SearchBuilder) Explicit(explicit bool) *SearchBuilder {
+	if explicit {
+		b.s.ExplicitContent = ExplicitYes
+	} else {
+		b.s.ExplicitContent = ExplicitNo
+	}
+	return b
+}
+
+// Build returns the Search built so far, or the error that
+// Search.Validate reports for it. The builder can be reused;
+// later calls don't affect the Searches already built.
+func (b *SearchBuilder) Build() (*Search, error) {
+	s := b.s.Clone()
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
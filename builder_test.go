@@ -0,0 +1,76 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchBuilder(t *testing.T) {
+	b := NewSearch("jack johnson").
+		Country("US").
+		Media(MediaMusic).
+		Entity(EntityMusicArtist).
+		Entity(EntityMusicVideo).
+		Attribute(AttributeArtistTerm).
+		Language("en_us").
+		Limit(25).
+		Offset(50).
+		Version(Version2).
+		Explicit(false)
+	s, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	want := &Search{
+		Term:            "jack johnson",
+		Country:         "US",
+		Media:           MediaMusic,
+		Entity:          EntityMusicArtist,
+		Entities:        []Entity{EntityMusicVideo},
+		Attribute:       AttributeArtistTerm,
+		Language:        "en_us",
+		Limit:           25,
+		Offset:          50,
+		Version:         Version2,
+		ExplicitContent: ExplicitNo,
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("Build=%+v\nwant %+v", s, want)
+	}
+
+	// Searches already built are unaffected by later calls.
+	b.Entity(EntityMusicArtist).Limit(5)
+	if g, w := len(s.Entities), 1; g != w {
+		t.Errorf("len(Entities)=%d want %d", g, w)
+	}
+	if g, w := s.Limit, uint(25); g != w {
+		t.Errorf("Limit=%d want %d", g, w)
+	}
+}
+
+func TestSearchBuilderValidates(t *testing.T) {
+	if _, err := NewSearch("").Build(); err != ErrMissingTerm {
+		t.Errorf("without a term: err=%v want %v", err, ErrMissingTerm)
+	}
+	if _, err := NewSearch("x").Media(MediaMovie).Entity(EntityPodcast).Build(); err == nil {
+		t.Error("expected an error for an entity that doesn't match the media")
+	}
+	if _, err := NewSearch("x").Version("3").Build(); err == nil {
+		t.Error("expected an error for an invalid version")
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// responseCache holds successful response bodies keyed by request
+// URL for a fixed TTL. Expired entries are dropped when looked up and
+// swept by a janitor goroutine, which runs until close is called. It
+// is safe for concurrent use and a nil *responseCache caches nothing.
+type responseCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+type cacheEntry struct {
+	blob    []byte
+	expires time.Time
+}
+
+// minSweepInterval bounds how often the cache janitor sweeps.
+const minSweepInterval = time.Second
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	rc := &responseCache{
+		ttl:     ttl,
+		entries: make(map[string]*cacheEntry),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	interval := ttl
+	if interval < minSweepInterval {
+		interval = minSweepInterval
+	}
+	go rc.janitor(interval)
+	return rc
+}
+
+// janitor sweeps the expired entries every interval until the cache is closed.
+func (rc *responseCache) janitor(interval time.Duration) {
+	defer close(rc.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rc.sweep()
+		case <-rc.stop:
+			return
+		}
+	}
+}
+
+func (rc *responseCache) sweep() {
+	now := time.Now()
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for key, entry := range rc.entries {
+		if !now.Before(entry.expires) {
+			delete(rc.entries, key)
+		}
+	}
+}
+
+// close stops the janitor and waits for it to exit.
+func (rc *responseCache) close() {
+	if rc == nil {
+		return
+	}
+	rc.closeOnce.Do(func() { close(rc.stop) })
+	<-rc.done
+}
+
+// lookup returns the cached body for key, if it hasn't expired.
+func (rc *responseCache) lookup(key string) ([]byte, bool) {
+	if rc == nil {
+		return nil, false
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !time.Now().Before(entry.expires) {
+		delete(rc.entries, key)
+		return nil, false
+	}
+	return entry.blob, true
+}
+
+func (rc *responseCache) store(key string, blob []byte) {
+	if rc == nil {
+		return
+	}
+
+	rc.mu.Lock()
+	rc.entries[key] = &cacheEntry{blob: blob, expires: time.Now().Add(rc.ttl)}
+	rc.mu.Unlock()
+}
+
+// validatorCache holds response bodies keyed by request URL along with
+// their ETag and Last-Modified validators, to revalidate them with
+// conditional requests. It keeps at most max entries, evicting the
+// least recently used one to make room. It is safe for concurrent use
+// and a nil *validatorCache caches nothing.
+type validatorCache struct {
+	max int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order holds the entries, most recently used first.
+	order *list.List
+}
+
+type validatedEntry struct {
+	key          string
+	etag         string
+	lastModified string
+	blob         []byte
+}
+
+// defaultMaxValidatedEntries is the number of responses
+// that WithConditionalCache keeps for revalidation.
+const defaultMaxValidatedEntries = 256
+
+func newValidatorCache(max int) *validatorCache {
+	if max < 1 {
+		max = 1
+	}
+	return &validatorCache{
+		max:     max,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// prepare makes req conditional on the validators cached for key, if
+// any, and returns the entry whose body a 304 response revalidates.
+func (vc *validatorCache) prepare(key string, req *http.Request) *validatedEntry {
+	if vc == nil {
+		return nil
+	}
+
+	vc.mu.Lock()
+	var entry *validatedEntry
+	if elem, ok := vc.entries[key]; ok {
+		vc.order.MoveToFront(elem)
+		entry = elem.Value.(*validatedEntry)
+	}
+	vc.mu.Unlock()
+
+	if entry == nil {
+		return nil
+	}
+	if entry.etag != "" {
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+	if entry.lastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.lastModified)
+	}
+	return entry
+}
+
+// store caches blob for key if header carries any validators,
+// evicting the least recently used entry if the cache is full.
+func (vc *validatorCache) store(key string, header http.Header, blob []byte) {
+	if vc == nil {
+		return
+	}
+	entry := &validatedEntry{
+		key:          key,
+		etag:         header.Get("ETag"),
+		lastModified: header.Get("Last-Modified"),
+		blob:         blob,
+	}
+	if entry.etag == "" && entry.lastModified == "" {
+		return
+	}
+
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if elem, ok := vc.entries[key]; ok {
+		elem.Value = entry
+		vc.order.MoveToFront(elem)
+		return
+	}
+	vc.entries[key] = vc.order.PushFront(entry)
+	for vc.order.Len() > vc.max {
+		oldest := vc.order.Back()
+		vc.order.Remove(oldest)
+		delete(vc.entries, oldest.Value.(*validatedEntry).key)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithCache(t *testing.T) {
+	var hits int32
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		fmt.Fprint(w, `{"resultCount": 1, "results": [{"trackId": 7}]}`)
+	}))
+	defer cst.Close()
+
+	client := NewClient(WithCache(time.Minute))
+	client.searchURL = cst.URL
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		sres, err := client.Search(ctx, &Search{Term: "cached"})
+		if err != nil {
+			t.Fatalf("#%d: Search: %v", i, err)
+		}
+		if len(sres.Results) != 1 || sres.Results[0].TrackId != 7 {
+			t.Errorf("#%d: results=%+v", i, sres.Results)
+		}
+	}
+	if g, w := atomic.LoadInt32(&hits), int32(1); g != w {
+		t.Errorf("backend hits=%d want %d", g, w)
+	}
+
+	// A different query is not served from the cache.
+	if _, err := client.Search(ctx, &Search{Term: "other"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if g, w := atomic.LoadInt32(&hits), int32(2); g != w {
+		t.Errorf("backend hits=%d want %d", g, w)
+	}
+}
+
+func TestResponseCacheExpiry(t *testing.T) {
+	rc := newResponseCache(10 * time.Millisecond)
+	defer rc.close()
+	rc.store("k", []byte(`{"resultCount": 0}`))
+	if _, ok := rc.lookup("k"); !ok {
+		t.Fatal("expected a cache hit before the ttl")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := rc.lookup("k"); ok {
+		t.Error("expected a cache miss after the ttl")
+	}
+}
+
+func TestWithConditionalCache(t *testing.T) {
+	const etag = `"v1"`
+	var conditional []string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = append(conditional, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		fmt.Fprint(w, `{"resultCount": 1, "results": [{"trackName": "Cached"}]}`)
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	client := NewClient(WithConditionalCache())
+	client.searchURL = cst.URL
+	for i := 0; i < 2; i++ {
+		sres, res, err := client.SearchRaw(ctx, &Search{Term: "x"})
+		if err != nil {
+			t.Fatalf("#%d: Search: %v", i, err)
+		}
+		if g, w := sres.Results[0].TrackName, "Cached"; g != w {
+			t.Errorf("#%d: TrackName=%q want %q", i, g, w)
+		}
+		if i == 1 && res.StatusCode != http.StatusNotModified {
+			t.Errorf("#%d: StatusCode=%d want %d", i, res.StatusCode, http.StatusNotModified)
+		}
+	}
+
+	if want := []string{"", etag}; !reflect.DeepEqual(conditional, want) {
+		t.Errorf("If-None-Match=%q want %q", conditional, want)
+	}
+}
+
+func TestValidatorCacheEviction(t *testing.T) {
+	header := http.Header{"Etag": {`"v1"`}}
+	vc := newValidatorCache(2)
+	vc.store("a", header, []byte("a"))
+	vc.store("b", header, []byte("b"))
+
+	// Using a makes b the least recently used entry.
+	req := httptest.NewRequest("GET", "/", nil)
+	if entry := vc.prepare("a", req); entry == nil {
+		t.Fatal("a: expected a cached entry")
+	}
+	vc.store("c", header, []byte("c"))
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		req := httptest.NewRequest("GET", "/", nil)
+		if got := vc.prepare(key, req) != nil; got != want {
+			t.Errorf("%s: cached=%t want %t", key, got, want)
+		}
+	}
+	if g, w := len(vc.entries), 2; g != w {
+		t.Errorf("len(entries)=%d want %d", g, w)
+	}
+	if g, w := vc.order.Len(), 2; g != w {
+		t.Errorf("order.Len()=%d want %d", g, w)
+	}
+}
+
+func TestClientCloseStopsCacheJanitor(t *testing.T) {
+	client := NewClient(WithCache(time.Minute))
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	select {
+	case <-client.cache.done:
+	case <-time.After(time.Second):
+		t.Fatal("the cache janitor is still running after Close")
+	}
+
+	// Closing twice, or a client without a cache, is harmless.
+	if err := client.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+	if err := new(Client).Close(); err != nil {
+		t.Errorf("zero Client: Close: %v", err)
+	}
+}
+
+func TestResponseCacheSweep(t *testing.T) {
+	rc := newResponseCache(time.Millisecond)
+	defer rc.close()
+	rc.store("k", []byte(`{"resultCount": 0}`))
+	time.Sleep(5 * time.Millisecond)
+	rc.sweep()
+
+	rc.mu.Lock()
+	n := len(rc.entries)
+	rc.mu.Unlock()
+	if n != 0 {
+		t.Errorf("%d entries left after the sweep want 0", n)
+	}
+}
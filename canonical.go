@@ -0,0 +1,110 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// CanonicalJSON marshals sr deterministically, for snapshot tests:
+// object keys are sorted, there is no insignificant whitespace and
+// numbers are formatted in their shortest form, so that equal
+// SearchResults always produce byte-identical JSON.
+func (sr *SearchResult) CanonicalJSON() ([]byte, error) {
+	blob, err := json.Marshal(sr)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(blob))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err := writeCanonical(buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case json.Number:
+		num, err := canonicalNumber(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(num)
+	default:
+		blob, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(blob)
+	}
+	return nil
+}
+
+// canonicalNumber formats integers exactly, so that large ids keep
+// their precision, and any other number in its shortest form.
+func canonicalNumber(n json.Number) (string, error) {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return strconv.FormatInt(i, 10), nil
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return strconv.FormatUint(u, 10), nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return "", fmt.Errorf("invalid number %q: %w", n, err)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64), nil
+}
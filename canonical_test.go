@@ -0,0 +1,53 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSearchResultCanonicalJSON(t *testing.T) {
+	// The same results, with their keys in a different
+	// order and their numbers formatted differently.
+	blobs := []string{
+		`{"resultCount": 1, "results": [{"trackId": 1440857781, "trackPrice": 1.29, "trackName": "One More Time", "genres": ["Dance", "Music"]}]}`,
+		`{"results": [{"genres": ["Dance", "Music"], "trackName": "One More Time", "trackPrice": 1.290, "trackId": "1440857781"}], "resultCount": 1}`,
+	}
+	var canonical [][]byte
+	for _, blob := range blobs {
+		var sres SearchResult
+		if err := json.Unmarshal([]byte(blob), &sres); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		got, err := sres.CanonicalJSON()
+		if err != nil {
+			t.Fatalf("CanonicalJSON: %v", err)
+		}
+		canonical = append(canonical, got)
+	}
+	if !bytes.Equal(canonical[0], canonical[1]) {
+		t.Errorf("canonical JSON differs:\n%s\n%s", canonical[0], canonical[1])
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(canonical[0], &decoded); err != nil {
+		t.Fatalf("canonical JSON is invalid: %v", err)
+	}
+	if !bytes.HasPrefix(canonical[0], []byte(`{"resultCount":1,"results":[{"artistId":0,`)) {
+		t.Errorf("keys are not sorted: %s", canonical[0])
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// acceptEncoding is sent with every request. Since it is set
+// explicitly, net/http leaves decompression to decompress.
+const acceptEncoding = "gzip, deflate"
+
+// decompress replaces the body of a gzip or deflate encoded
+// response with one that yields the decoded content. Responses
+// without a body, such as a 304 revalidating a cached body, are
+// left as they are whatever their Content-Encoding.
+func decompress(res *http.Response) error {
+	switch {
+	case res.StatusCode == http.StatusNoContent, res.StatusCode == http.StatusNotModified, res.ContentLength == 0:
+		return nil
+	}
+
+	var zr io.ReadCloser
+	var err error
+	switch encoding := strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		return nil
+	case "gzip", "x-gzip":
+		zr, err = gzip.NewReader(res.Body)
+	case "deflate":
+		zr, err = zlib.NewReader(res.Body)
+	default:
+		return fmt.Errorf("unsupported Content-Encoding %q", encoding)
+	}
+	if err == io.EOF {
+		// An empty body, sent without a Content-Length.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	res.Body = &decompressedBody{ReadCloser: zr, compressed: res.Body}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+	return nil
+}
+
+// decompressedBody reads through a decompressor and
+// closes both it and the underlying compressed body.
+type decompressedBody struct {
+	io.ReadCloser
+	compressed io.Closer
+}
+
+func (db *decompressedBody) Close() error {
+	err := db.ReadCloser.Close()
+	if cerr := db.compressed.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressedResponses(t *testing.T) {
+	const body = `{"resultCount": 1, "results": [{"trackId": 1, "trackName": "Compressed"}]}`
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ae := r.Header.Get("Accept-Encoding"); !strings.Contains(ae, "gzip") {
+			http.Error(w, "gzip not advertised: "+ae, http.StatusBadRequest)
+			return
+		}
+
+		encoding := r.URL.Query().Get("encoding")
+		if r.URL.Path == "/lookup" {
+			encoding = "gzip"
+		}
+		var zw io.WriteCloser
+		switch encoding {
+		case "gzip":
+			zw = gzip.NewWriter(w)
+		case "deflate":
+			zw = zlib.NewWriter(w)
+		default:
+			io.WriteString(w, body)
+			return
+		}
+		w.Header().Set("Content-Encoding", encoding)
+		io.WriteString(zw, body)
+		zw.Close()
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	client := &Client{searchURL: cst.URL + "/search", lookupURL: cst.URL + "/lookup"}
+	for _, encoding := range []string{"gzip", "deflate", ""} {
+		s := &Search{Term: "x", Extra: map[string][]string{"encoding": {encoding}}}
+		sres, err := client.Search(ctx, s)
+		if err != nil {
+			t.Fatalf("encoding=%q: Search: %v", encoding, err)
+		}
+		if g, w := sres.Results[0].TrackName, "Compressed"; g != w {
+			t.Errorf("encoding=%q: TrackName=%q want %q", encoding, g, w)
+		}
+	}
+
+	res, err := client.LookupOne(ctx, "1")
+	if err != nil {
+		t.Fatalf("LookupOne: %v", err)
+	}
+	if g, w := res.TrackName, "Compressed"; g != w {
+		t.Errorf("lookup: TrackName=%q want %q", g, w)
+	}
+}
+
+func TestCompressedNotModified(t *testing.T) {
+	const etag = `"v1"`
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		zw := gzip.NewWriter(w)
+		io.WriteString(zw, `{"resultCount": 1, "results": [{"trackName": "Cached"}]}`)
+		zw.Close()
+	}))
+	defer cst.Close()
+
+	client := NewClient(WithConditionalCache())
+	client.searchURL = cst.URL
+	for i := 0; i < 2; i++ {
+		sres, err := client.Search(context.Background(), &Search{Term: "x"})
+		if err != nil {
+			t.Fatalf("#%d: Search: %v", i, err)
+		}
+		if g, w := sres.Results[0].TrackName, "Cached"; g != w {
+			t.Errorf("#%d: TrackName=%q want %q", i, g, w)
+		}
+	}
+}
+
+func TestUnsupportedEncodingIsNetworkError(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		io.WriteString(w, "not really brotli")
+	}))
+	defer cst.Close()
+
+	client := &Client{searchURL: cst.URL}
+	_, err := client.Search(context.Background(), &Search{Term: "x"})
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) {
+		t.Errorf("err=%v (%T) want a *NetworkError", err, err)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import "context"
+
+type countryKey struct{}
+
+// ContextWithCountry returns a copy of ctx that makes searches and
+// lookups sent with it use the store of country, e.g. "GB", unless
+// their Search or LookupParams set a Country of their own.
+func ContextWithCountry(ctx context.Context, country Country) context.Context {
+	return context.WithValue(ctx, countryKey{}, country)
+}
+
+// countryFromContext returns the country set with ContextWithCountry, if any.
+func countryFromContext(ctx context.Context) Country {
+	country, _ := ctx.Value(countryKey{}).(Country)
+	return country
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestContextWithCountry(t *testing.T) {
+	var countries []string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countries = append(countries, r.URL.Query().Get("country"))
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	client := &Client{searchURL: cst.URL}
+	ctx := ContextWithCountry(context.Background(), "GB")
+	s := &Search{Term: "x"}
+	if _, err := client.Search(ctx, s); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	// A country set on the Search takes precedence.
+	if _, err := client.Search(ctx, &Search{Term: "x", Country: "JP"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if _, err := client.Search(context.Background(), s); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	if want := []string{"GB", "JP", ""}; !reflect.DeepEqual(countries, want) {
+		t.Errorf("countries=%q want %q", countries, want)
+	}
+	if s.Country != "" {
+		t.Errorf("Search.Country=%q, the context country leaked into the Search", s.Country)
+	}
+}
+
+func TestContextWithCountryLookup(t *testing.T) {
+	var countries []string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countries = append(countries, r.URL.Query().Get("country"))
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	client := &Client{lookupURL: cst.URL}
+	ctx := ContextWithCountry(context.Background(), "GB")
+	if _, err := client.SearchById(ctx, "1"); err != nil {
+		t.Fatalf("SearchById: %v", err)
+	}
+	if _, err := client.Search(ctx, &Search{Id: "1"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	// A country set on the params takes precedence.
+	params := &LookupParams{Country: "JP"}
+	if _, err := client.LookupWithParams(ctx, "1", params); err != nil {
+		t.Fatalf("LookupWithParams: %v", err)
+	}
+
+	if want := []string{"GB", "GB", "JP"}; !reflect.DeepEqual(countries, want) {
+		t.Errorf("countries=%q want %q", countries, want)
+	}
+}
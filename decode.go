@@ -0,0 +1,127 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UnmarshalJSON decodes a result, accepting its numeric ids
+// and prices both as JSON numbers and as JSON strings, and its
+// releaseDate as RFC 3339, as a date alone or as empty.
+func (r *Result) UnmarshalJSON(b []byte) error {
+	type result Result // Has no UnmarshalJSON, avoiding recursion.
+	shadow := struct {
+		*result
+		TrackId            flexUint64  `json:"trackId"`
+		CollectionId       flexUint64  `json:"collectionId"`
+		ArtistId           flexUint64  `json:"artistId"`
+		CollectionArtistId flexUint64  `json:"collectionArtistId"`
+		TrackPrice         flexFloat64 `json:"trackPrice"`
+		CollectionPrice    flexFloat64 `json:"collectionPrice"`
+		ReleaseDate        flexTime    `json:"releaseDate"`
+	}{result: (*result)(r)}
+	if err := json.Unmarshal(b, &shadow); err != nil {
+		return err
+	}
+
+	r.TrackId = uint64(shadow.TrackId)
+	r.CollectionId = uint64(shadow.CollectionId)
+	r.ArtistId = uint64(shadow.ArtistId)
+	r.CollectionArtistId = uint64(shadow.CollectionArtistId)
+	r.TrackPrice = float64(shadow.TrackPrice)
+	r.CollectionPrice = float64(shadow.CollectionPrice)
+	r.ReleaseDate = time.Time(shadow.ReleaseDate)
+	return nil
+}
+
+// flexUint64 is a uint64 that decodes from a JSON number or string.
+type flexUint64 uint64
+
+func (fu *flexUint64) UnmarshalJSON(b []byte) error {
+	str, err := unquoteNumber(b)
+	if err != nil || str == "" {
+		return err
+	}
+	u, err := strconv.ParseUint(str, 10, 64)
+	if err != nil {
+		return err
+	}
+	*fu = flexUint64(u)
+	return nil
+}
+
+// flexFloat64 is a float64 that decodes from a JSON number or string.
+type flexFloat64 float64
+
+func (ff *flexFloat64) UnmarshalJSON(b []byte) error {
+	str, err := unquoteNumber(b)
+	if err != nil || str == "" {
+		return err
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return err
+	}
+	*ff = flexFloat64(f)
+	return nil
+}
+
+// flexTime is a time.Time that decodes from an RFC 3339 or a
+// date-only JSON string, and from null or "" as the zero time.
+type flexTime time.Time
+
+func (ft *flexTime) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	if str = strings.TrimSpace(str); str == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		var derr error
+		if t, derr = time.Parse("2006-01-02", str); derr != nil {
+			return err
+		}
+	}
+	*ft = flexTime(t)
+	return nil
+}
+
+// unquoteNumber returns the number that b holds as a JSON number or
+// string, or "" if b is null or an empty string.
+func unquoteNumber(b []byte) (string, error) {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return "", nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		var str string
+		if err := json.Unmarshal(b, &str); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(str), nil
+	}
+	return string(b), nil
+}
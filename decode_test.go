@@ -0,0 +1,124 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResultDecodesStringIds(t *testing.T) {
+	asNumbers := `{"trackId": 123, "collectionId": 456, "artistId": 789, "collectionArtistId": 10, "trackName": "Song"}`
+	asStrings := `{"trackId": "123", "collectionId": "456", "artistId": "789", "collectionArtistId": "10", "trackName": "Song"}`
+
+	var fromNumbers, fromStrings Result
+	if err := json.Unmarshal([]byte(asNumbers), &fromNumbers); err != nil {
+		t.Fatalf("numbers: %v", err)
+	}
+	if err := json.Unmarshal([]byte(asStrings), &fromStrings); err != nil {
+		t.Fatalf("strings: %v", err)
+	}
+	if !reflect.DeepEqual(fromNumbers, fromStrings) {
+		t.Errorf("decoded differently:\nnumbers: %+v\nstrings: %+v", fromNumbers, fromStrings)
+	}
+
+	want := Result{TrackId: 123, CollectionId: 456, ArtistId: 789, CollectionArtistId: 10, TrackName: "Song"}
+	if !reflect.DeepEqual(fromNumbers, want) {
+		t.Errorf("got %+v\nwant %+v", fromNumbers, want)
+	}
+
+	var bad Result
+	if err := json.Unmarshal([]byte(`{"trackId": "12ab"}`), &bad); err == nil {
+		t.Error("expected an error for a non-numeric string id")
+	}
+}
+
+func TestResultDecodesAlbumPosition(t *testing.T) {
+	var res Result
+	blob := `{
+		"wrapperType": "track",
+		"kind": "song",
+		"trackName": "Digital Love",
+		"trackNumber": 3,
+		"trackCount": 14,
+		"discNumber": 1,
+		"discCount": 1
+	}`
+	if err := json.Unmarshal([]byte(blob), &res); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	got := [...]uint{res.TrackNumber, res.TrackCount, res.DiscNumber, res.DiscCount}
+	if want := [...]uint{3, 14, 1, 1}; got != want {
+		t.Errorf("trackNumber, trackCount, discNumber, discCount=%v want %v", got, want)
+	}
+}
+
+func TestResultDecodesStringPrices(t *testing.T) {
+	blobs := []string{
+		`{"trackPrice": 1.29, "collectionPrice": 9.99}`,
+		`{"trackPrice": "1.29", "collectionPrice": "9.99"}`,
+	}
+	for _, blob := range blobs {
+		var res Result
+		if err := json.Unmarshal([]byte(blob), &res); err != nil {
+			t.Errorf("%s: Unmarshal: %v", blob, err)
+			continue
+		}
+		if g, w := res.TrackPrice, 1.29; g != w {
+			t.Errorf("%s: TrackPrice=%v want %v", blob, g, w)
+		}
+		if g, w := res.CollectionPrice, 9.99; g != w {
+			t.Errorf("%s: CollectionPrice=%v want %v", blob, g, w)
+		}
+	}
+
+	var bad Result
+	if err := json.Unmarshal([]byte(`{"trackPrice": "free"}`), &bad); err == nil {
+		t.Error("expected an error for a non-numeric string price")
+	}
+}
+
+func TestResultDecodesReleaseDate(t *testing.T) {
+	tests := []struct {
+		releaseDate string
+		want        time.Time
+	}{
+		{`"2005-03-01T08:00:00Z"`, time.Date(2005, 3, 1, 8, 0, 0, 0, time.UTC)},
+		{`"2005-03-01"`, time.Date(2005, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{`""`, time.Time{}},
+		{`null`, time.Time{}},
+	}
+	for _, tt := range tests {
+		var res Result
+		blob := `{"trackName": "Song", "releaseDate": ` + tt.releaseDate + `}`
+		if err := json.Unmarshal([]byte(blob), &res); err != nil {
+			t.Errorf("%s: Unmarshal: %v", tt.releaseDate, err)
+			continue
+		}
+		if !res.ReleaseDate.Equal(tt.want) {
+			t.Errorf("%s: ReleaseDate=%v want %v", tt.releaseDate, res.ReleaseDate, tt.want)
+		}
+		if g, w := res.TrackName, "Song"; g != w {
+			t.Errorf("%s: TrackName=%q want %q", tt.releaseDate, g, w)
+		}
+	}
+
+	var bad Result
+	if err := json.Unmarshal([]byte(`{"releaseDate": "March 2005"}`), &bad); err == nil {
+		t.Error("expected an error for an unrecognized releaseDate")
+	}
+}
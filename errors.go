@@ -0,0 +1,121 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// NetworkError is returned when a request couldn't be sent or its
+// response couldn't be read, e.g. because the connection failed.
+// Such failures are usually worth retrying.
+type NetworkError struct {
+	URL string
+	Err error
+}
+
+func (e *NetworkError) Error() string { return e.Err.Error() }
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+// DecodeError is returned when a response
+// isn't the JSON that the API should send.
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string { return "decoding response: " + e.Err.Error() }
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// APIError is returned for responses with a non-2XX status.
+type APIError struct {
+	StatusCode int
+	Status     string
+
+	// Message is the API's "errorMessage", if the body carried one.
+	Message string
+
+	// Body is the start of the response body, of which
+	// Error quotes at most the first maxSnippetBytes.
+	Body []byte
+}
+
+func (e *APIError) Error() string {
+	switch {
+	case e.Message != "":
+		return fmt.Sprintf("status: %s: %s", e.Status, e.Message)
+	case len(e.Body) > 0:
+		snippet := e.Body
+		if len(snippet) > maxSnippetBytes {
+			snippet = snippet[:maxSnippetBytes]
+		}
+		return fmt.Sprintf("status: %s: %s", e.Status, snippet)
+	default:
+		return fmt.Sprintf("status: %s", e.Status)
+	}
+}
+
+// maxErrorBodyBytes caps how much of a failed response's body is read.
+const maxErrorBodyBytes = 64 << 10
+
+// responseError builds the *APIError for a non-2XX response, including
+// the API's "errorMessage" if the body carries one or else the raw body.
+func responseError(res *http.Response) error {
+	blob, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodyBytes))
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Body:       bytes.TrimSpace(blob),
+	}
+
+	body := new(struct {
+		ErrorMessage string `json:"errorMessage"`
+	})
+	if err := json.Unmarshal(apiErr.Body, body); err == nil {
+		apiErr.Message = body.ErrorMessage
+	}
+	return apiErr
+}
+
+// maxSnippetBytes caps how much of an unexpected body an error quotes.
+const maxSnippetBytes = 200
+
+// htmlError returns an *APIError for a response that is an HTML page
+// instead of JSON, as the API sends with a 200 status during outages,
+// or nil for any other response.
+func htmlError(res *http.Response, blob []byte) error {
+	body := bytes.TrimSpace(blob)
+	isHTML := strings.Contains(res.Header.Get("Content-Type"), "html")
+	if !isHTML && !bytes.HasPrefix(body, []byte("<")) {
+		return nil
+	}
+	if len(body) > maxErrorBodyBytes {
+		body = body[:maxErrorBodyBytes]
+	}
+	snippet := body
+	if len(snippet) > maxSnippetBytes {
+		snippet = snippet[:maxSnippetBytes]
+	}
+	return &APIError{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Message:    fmt.Sprintf("unexpected HTML response instead of JSON: %q", snippet),
+		Body:       body,
+	}
+}
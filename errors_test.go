@@ -0,0 +1,115 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTypedErrors(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("term") {
+		case "api":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errorMessage": "Rate limit exceeded"}`)
+		case "decode":
+			fmt.Fprint(w, `{"resultCount": 1, "results": [`)
+		default:
+			fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+		}
+	}))
+	ctx := context.Background()
+	client := &Client{searchURL: cst.URL}
+
+	_, err := client.Search(ctx, &Search{Term: "api"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err=%v (%T) want an *APIError", err, err)
+	}
+	if g, w := apiErr.StatusCode, http.StatusForbidden; g != w {
+		t.Errorf("StatusCode=%d want %d", g, w)
+	}
+	if g, w := apiErr.Message, "Rate limit exceeded"; g != w {
+		t.Errorf("Message=%q want %q", g, w)
+	}
+	if g, w := string(apiErr.Body), `{"errorMessage": "Rate limit exceeded"}`; g != w {
+		t.Errorf("Body=%q want %q", g, w)
+	}
+
+	_, err = client.Search(ctx, &Search{Term: "decode"})
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Errorf("err=%v (%T) want a *DecodeError", err, err)
+	}
+
+	// Once the server is gone, requests fail to connect.
+	cst.Close()
+	_, err = client.Search(ctx, &Search{Term: "network"})
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) {
+		t.Fatalf("err=%v (%T) want a *NetworkError", err, err)
+	}
+	if netErr.URL == "" {
+		t.Error("NetworkError.URL is empty")
+	}
+}
+
+func TestHTMLResponse(t *testing.T) {
+	const page = "<!DOCTYPE html>\n<html><head><title>Service Unavailable</title></head><body>Please try again later.</body></html>"
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("term") == "untyped" {
+			w.Header().Set("Content-Type", "text/javascript")
+		} else {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		fmt.Fprint(w, page)
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	client := &Client{searchURL: cst.URL}
+	for _, term := range []string{"typed", "untyped"} {
+		_, err := client.Search(ctx, &Search{Term: term})
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("%s: err=%v (%T) want an *APIError", term, err, err)
+			continue
+		}
+		if g, w := apiErr.StatusCode, http.StatusOK; g != w {
+			t.Errorf("%s: StatusCode=%d want %d", term, g, w)
+		}
+		if !strings.Contains(apiErr.Error(), "unexpected HTML response") || !strings.Contains(apiErr.Error(), "Service Unavailable") {
+			t.Errorf("%s: err=%q should explain the HTML response and quote it", term, apiErr)
+		}
+	}
+
+	for _, term := range []string{"typed", "untyped"} {
+		err := client.SearchStream(ctx, &Search{Term: term}, func(*Result) error { return nil })
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("%s: SearchStream: err=%v (%T) want an *APIError", term, err, err)
+			continue
+		}
+		if !strings.Contains(apiErr.Error(), "Service Unavailable") {
+			t.Errorf("%s: SearchStream: err=%q should quote the HTML response", term, apiErr)
+		}
+	}
+}
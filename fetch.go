@@ -0,0 +1,98 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+var (
+	errNoPreviewURL = errors.New("result has no previewUrl")
+	errNoArtworkURL = errors.New("result has no artwork URL")
+	errNoFeedURL    = errors.New("result has no feedUrl")
+	errNotPodcast   = errors.New("result is not a podcast")
+)
+
+// FetchPreview downloads the result's preview, a short audio or
+// video clip, through c. The caller must close the returned reader.
+// If c is nil, DefaultClient is used.
+func (r *Result) FetchPreview(ctx context.Context, c *Client) (io.ReadCloser, error) {
+	if r.PreviewURL == "" {
+		return nil, errNoPreviewURL
+	}
+	if c == nil {
+		c = DefaultClient
+	}
+	res, err := c.do(ctx, r.PreviewURL)
+	if err != nil {
+		return nil, err
+	}
+	if !statusOK(res.StatusCode) {
+		defer res.Body.Close()
+		return nil, responseError(res)
+	}
+	return res.Body, nil
+}
+
+// FetchArtwork downloads and decodes the result's artwork at size×size
+// pixels through c, see Result.ArtworkURL. JPEG and PNG artwork can be
+// decoded. If c is nil, DefaultClient is used.
+func (r *Result) FetchArtwork(ctx context.Context, c *Client, size int) (image.Image, error) {
+	artworkURL := r.ArtworkURL(size)
+	if artworkURL == "" {
+		return nil, errNoArtworkURL
+	}
+	if c == nil {
+		c = DefaultClient
+	}
+	res, err := c.do(ctx, artworkURL)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if !statusOK(res.StatusCode) {
+		return nil, responseError(res)
+	}
+	img, _, err := image.Decode(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("decoding artwork %q: %w", artworkURL, err)
+	}
+	return img, nil
+}
+
+// FetchFeed downloads the RSS feed of a podcast result through c and
+// returns it as is, for the caller to parse. It fails if the result
+// isn't a podcast or has no feedUrl. If c is nil, DefaultClient is used.
+func (r *Result) FetchFeed(ctx context.Context, c *Client) ([]byte, error) {
+	if r.Kind != KindPodcast {
+		return nil, errNotPodcast
+	}
+	if r.FeedURL == "" {
+		return nil, errNoFeedURL
+	}
+	if c == nil {
+		c = DefaultClient
+	}
+	blob, _, err := c.get(ctx, r.FeedURL)
+	return blob, err
+}
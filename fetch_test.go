@@ -0,0 +1,131 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchPreview(t *testing.T) {
+	const preview = "\x00\x00\x00\x20ftypM4A fake preview bytes"
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/x-m4a")
+		io.WriteString(w, preview)
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	client := NewClient()
+	res := &Result{PreviewURL: cst.URL + "/preview.m4a"}
+	rc, err := res.FetchPreview(ctx, client)
+	if err != nil {
+		t.Fatalf("FetchPreview: %v", err)
+	}
+	defer rc.Close()
+
+	blob, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if g, w := string(blob), preview; g != w {
+		t.Errorf("preview=%q want %q", g, w)
+	}
+
+	if _, err := new(Result).FetchPreview(ctx, client); err != errNoPreviewURL {
+		t.Errorf("without previewUrl: err=%v want %v", err, errNoPreviewURL)
+	}
+}
+
+func TestFetchArtwork(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	src.Set(1, 1, color.RGBA{R: 0xff, A: 0xff})
+	var pngBytes bytes.Buffer
+	if err := png.Encode(&pngBytes, src); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+
+	var paths []string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/2x2bb.png") {
+			w.Write(pngBytes.Bytes())
+			return
+		}
+		io.WriteString(w, "not an image")
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	client := NewClient()
+	res := &Result{ArtworkURL100Px: cst.URL + "/art/100x100bb.png"}
+
+	img, err := res.FetchArtwork(ctx, client, 2)
+	if err != nil {
+		t.Fatalf("FetchArtwork: %v", err)
+	}
+	if g, w := img.Bounds(), src.Bounds(); g != w {
+		t.Errorf("Bounds=%v want %v", g, w)
+	}
+	if g, w := color.RGBAModel.Convert(img.At(1, 1)), src.At(1, 1); g != w {
+		t.Errorf("pixel (1, 1)=%v want %v", g, w)
+	}
+	if g, w := paths[0], "/art/2x2bb.png"; g != w {
+		t.Errorf("path=%q want %q", g, w)
+	}
+
+	if _, err := res.FetchArtwork(ctx, client, 100); err == nil {
+		t.Error("expected an error for bytes that aren't an image")
+	}
+	if _, err := new(Result).FetchArtwork(ctx, client, 100); err != errNoArtworkURL {
+		t.Errorf("without artwork: err=%v want %v", err, errNoArtworkURL)
+	}
+}
+
+func TestFetchFeed(t *testing.T) {
+	const feed = `<?xml version="1.0"?><rss version="2.0"><channel><title>Fake</title></channel></rss>`
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		io.WriteString(w, feed)
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	client := NewClient()
+	res := &Result{Kind: KindPodcast, FeedURL: cst.URL + "/feed.xml"}
+	blob, err := res.FetchFeed(ctx, client)
+	if err != nil {
+		t.Fatalf("FetchFeed: %v", err)
+	}
+	if g, w := string(blob), feed; g != w {
+		t.Errorf("feed=%q want %q", g, w)
+	}
+
+	if _, err := (&Result{Kind: KindPodcast}).FetchFeed(ctx, client); err != errNoFeedURL {
+		t.Errorf("without feedUrl: err=%v want %v", err, errNoFeedURL)
+	}
+	song := &Result{Kind: KindSong, FeedURL: cst.URL + "/feed.xml"}
+	if _, err := song.FetchFeed(ctx, client); err != errNotPodcast {
+		t.Errorf("song: err=%v want %v", err, errNotPodcast)
+	}
+}
@@ -1,3 +1,8 @@
 module github.com/orijtech/itunes
 
-require go.opencensus.io v0.19.0
+go 1.24.0
+
+require (
+	go.opencensus.io v0.19.0
+	golang.org/x/time v0.14.0
+)
@@ -21,81 +21,807 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.opencensus.io/plugin/ochttp"
-	"go.opencensus.io/trace"
+	"go.opencensus.io/stats"
+	"golang.org/x/time/rate"
 )
 
-type Client int
+// Client talks to the iTunes Search API. Its zero value is ready to use.
+//
+// A Client is safe for concurrent use by multiple goroutines. Its
+// configuration is fixed by the options passed to NewClient and any
+// state it keeps across requests, such as its cache, is synchronized.
+type Client struct {
+	// searchURL and lookupURL override the default
+	// endpoints and are only set in tests.
+	searchURL string
+	lookupURL string
+
+	defaultTimeout time.Duration
+	userAgent      string
+	storefront     string
+	header         http.Header
+	transport      http.RoundTripper
+	tap            func(*http.Request, *http.Response)
+	cache          *responseCache
+	validators     *validatorCache
+	flights        *flightGroup
+	limiter        *rate.Limiter
+	observeRequest func(*http.Request)
+	requestIDKey   interface{}
+	logger         *slog.Logger
+
+	// maxRedirects is the number of redirects to follow, where
+	// 0 means defaultMaxRedirects and a negative value means none.
+	maxRedirects int
+
+	maxResponseBytes int64
+	bodyReadTimeout  time.Duration
+	jsonpCallback    string
+
+	normalizeURLs     bool
+	normalizeArtwork  bool
+	disableTracing    bool
+	strictResultCount bool
+	errorOnEmpty      bool
+	normalizeTokens   bool
+	disableLookupOnID bool
+}
+
+const (
+	baseURL   = "https://itunes.apple.com/search"
+	lookupURL = "https://itunes.apple.com/lookup"
+
+	libraryVersion   = "0.1.0"
+	defaultUserAgent = "orijtech-itunes/" + libraryVersion
+)
+
+func (c *Client) searchEndpoint() string {
+	if c.searchURL != "" {
+		return c.searchURL
+	}
+	return baseURL
+}
+
+func (c *Client) lookupEndpoint() string {
+	if c.lookupURL != "" {
+		return c.lookupURL
+	}
+	return lookupURL
+}
+
+func (c *Client) userAgentOrDefault() string {
+	if c.userAgent != "" {
+		return c.userAgent
+	}
+	return defaultUserAgent
+}
+
+// nopLogger discards everything, for clients without a logger.
+var nopLogger = slog.New(slog.DiscardHandler)
+
+func (c *Client) loggerOrDefault() *slog.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return nopLogger
+}
 
-const baseURL = "https://itunes.apple.com/search"
+// DefaultClient is the Client used by Find and Lookup.
+var DefaultClient = new(Client)
+
+// Find searches using DefaultClient. It is the package-level
+// counterpart of Client.Search, for one-off calls.
+func Find(ctx context.Context, s *Search) (*SearchResult, error) {
+	return DefaultClient.Search(ctx, s)
+}
+
+// Lookup looks up id using DefaultClient. It is the package-level
+// counterpart of Client.SearchById, for one-off calls.
+func Lookup(ctx context.Context, id string) (*SearchResult, error) {
+	return DefaultClient.SearchById(ctx, id)
+}
 
 var errUnimplemented = errors.New("unimplemented")
 var errNilSearch = errors.New("nil search")
+var errEmptyQuery = errors.New("empty query")
+
+// ErrMissingTerm is returned for a Search that has neither a Term, Terms nor an Id.
+var ErrMissingTerm = errors.New("search has neither a term nor an id")
+
+// Search searches for s. Any opts override the client's own options
+// for this call only, see CallOption.
+func (c *Client) Search(ctx context.Context, s *Search, opts ...CallOption) (*SearchResult, error) {
+	sres, _, err := c.withCallOptions(opts).SearchRaw(ctx, s)
+	return sres, err
+}
 
-func (c *Client) Search(ctx context.Context, s *Search) (*SearchResult, error) {
-	ctx, span := trace.StartSpan(ctx, "itunes.(*Client).Search")
+// withCallOptions returns c if there are no opts, or else a shallow
+// copy of c with opts applied, so that they don't affect c's other
+// calls. The copy shares c's cache, rate limiter and other state.
+func (c *Client) withCallOptions(opts []CallOption) *Client {
+	if len(opts) == 0 {
+		return c
+	}
+	var co callOptions
+	for _, opt := range opts {
+		opt(&co)
+	}
+
+	cc := *c
+	if co.timeout > 0 {
+		cc.defaultTimeout = co.timeout
+	}
+	if co.priority {
+		cc.limiter = nil
+	}
+	if len(co.header) > 0 {
+		cc.header = c.header.Clone()
+		if cc.header == nil {
+			cc.header = make(http.Header)
+		}
+		for key, values := range co.header {
+			cc.header[key] = append(cc.header[key], values...)
+		}
+	}
+	return &cc
+}
+
+// SearchRaw is like Search but also returns the HTTP response that
+// the results were decoded from, so that its status code and headers
+// can be inspected. The response body has already been drained and
+// closed. The response is returned even when its status is not 2XX,
+// but is nil when the results were served from the client's cache.
+func (c *Client) SearchRaw(ctx context.Context, s *Search) (*SearchResult, *http.Response, error) {
+	ctx, span := startSpan(c.traceContext(ctx), "itunes.(*Client).Search")
 	defer span.End()
 
+	if s != nil {
+		span.AddAttributes(searchAttributes(s)...)
+	}
+
+	start := time.Now()
+	sres, res, err := c.searchRaw(ctx, s)
+	recordStats(ctx, start, sres, err)
+	annotateSpan(span, sres, err)
+	if err == nil && c.errorOnEmpty && sres.ResultCount == 0 && len(sres.Results) == 0 {
+		return nil, res, ErrNoResults
+	}
+	return sres, res, err
+}
+
+func (c *Client) searchRaw(ctx context.Context, s *Search) (*SearchResult, *http.Response, error) {
+	s, err := c.prepareSearch(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := c.withDefaultTimeout(ctx)
+	defer cancel()
+
+	if c.looksUpID(s) {
+		return c.lookupRaw(ctx, s.Id, s.lookupParams())
+	}
+
+	searchURL, err := c.searchRequestURL(ctx, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	if blob, ok := c.cache.lookup(searchURL); ok {
+		sres, err := c.decode(ctx, blob)
+		return sres, nil, err
+	}
+	blob, res, err := c.fetch(ctx, searchURL)
+	if err != nil {
+		return nil, res, err
+	}
+
+	sres, err := c.decode(ctx, blob)
+	if err != nil {
+		return nil, res, err
+	}
+	c.cache.store(searchURL, blob)
+	return sres, res, nil
+}
+
+func (c *Client) searchRequestURL(ctx context.Context, s *Search) (string, error) {
+	if country := countryFromContext(ctx); s.Country == "" && country != "" {
+		s = s.Clone()
+		s.Country = country
+	}
+	queryString, err := s.EncodeQuery(c.traceContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	if c.jsonpCallback != "" {
+		queryString += "&" + url.Values{"callback": {c.jsonpCallback}}.Encode()
+	}
+	return fmt.Sprintf("%s?%s", c.searchEndpoint(), queryString), nil
+}
+
+// looksUpID reports whether s is sent as a lookup of its Id.
+func (c *Client) looksUpID(s *Search) bool {
+	return s.Id != "" && !c.disableLookupOnID
+}
+
+// requestURL returns the URL to search for s, or to look up its Id.
+func (c *Client) requestURL(ctx context.Context, s *Search) (string, error) {
+	if c.looksUpID(s) {
+		return c.lookupRequestURL(ctx, s.Id, s.lookupParams()), nil
+	}
+	return c.searchRequestURL(ctx, s)
+}
+
+func (c *Client) lookupRequestURL(ctx context.Context, id string, params *LookupParams) string {
+	values := params.urlValues(id)
+	if country := countryFromContext(ctx); values.Get("country") == "" && country != "" {
+		values.Set("country", string(country))
+	}
+	if c.jsonpCallback != "" {
+		values.Set("callback", c.jsonpCallback)
+	}
+	return fmt.Sprintf("%s?%s", c.lookupEndpoint(), values.Encode())
+}
+
+// prepareSearch returns s as it is to be sent, after applying the
+// client's normalization to it, or an error if it isn't valid.
+func (c *Client) prepareSearch(s *Search) (*Search, error) {
 	if s == nil {
 		return nil, errNilSearch
 	}
-
-	if s.Id != "" {
-		return c.SearchById(ctx, s.Id)
+	if c.normalizeTokens {
+		s = s.withCanonicalTokens()
 	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
 
-	urlValues, err := valueToURLValues(ctx, s)
+// BuildRequest returns the request that Search would send for s,
+// with its URL and headers set as per the client, without sending
+// it. It is meant for debugging and tests; the request observer is
+// not called.
+func (c *Client) BuildRequest(ctx context.Context, s *Search) (*http.Request, error) {
+	s, err := c.prepareSearch(s)
 	if err != nil {
 		return nil, err
 	}
-	queryString := urlValues.Encode()
-	searchURL := fmt.Sprintf("%s?%s", baseURL, queryString)
-	req, err := http.NewRequest("GET", searchURL, nil)
+	rawURL, err := c.requestURL(ctx, s)
 	if err != nil {
 		return nil, err
 	}
-	req = req.WithContext(ctx)
+	return c.newRequest(ctx, rawURL)
+}
+
+// SearchInto is like Search but unmarshals the response into out, e.g.
+// a struct modeling fields that Result doesn't, instead of a SearchResult.
+// out is left untouched if the response is empty.
+func (c *Client) SearchInto(ctx context.Context, s *Search, out interface{}) error {
+	s, err := c.prepareSearch(s)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.withDefaultTimeout(ctx)
+	defer cancel()
+
+	rawURL, err := c.requestURL(ctx, s)
+	if err != nil {
+		return err
+	}
+	blob, cached := c.cache.lookup(rawURL)
+	if !cached {
+		if blob, _, err = c.fetch(ctx, rawURL); err != nil {
+			return err
+		}
+	}
+
+	body := blob
+	if c.jsonpCallback != "" {
+		body = stripJSONP(body, c.jsonpCallback)
+	}
+	if body = bytes.TrimSpace(body); len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return &DecodeError{Err: err}
+	}
+	if !cached {
+		c.cache.store(rawURL, blob)
+	}
+	return nil
+}
+
+// SearchRawQuery searches with rawQuery, e.g. "term=jack+johnson&limit=5",
+// appended verbatim to the search endpoint instead of a Search, for
+// queries that Search can't express and for debugging. rawQuery must
+// be a well-formed query string; it is otherwise not validated.
+func (c *Client) SearchRawQuery(ctx context.Context, rawQuery string) (*SearchResult, error) {
+	if rawQuery == "" {
+		return nil, errEmptyQuery
+	}
+	if _, err := url.ParseQuery(rawQuery); err != nil {
+		return nil, fmt.Errorf("malformed query: %w", err)
+	}
 
-	client := &http.Client{Transport: &ochttp.Transport{}}
-	res, err := client.Do(req)
+	ctx, cancel := c.withDefaultTimeout(ctx)
+	defer cancel()
+
+	start := time.Now()
+	sres, err := c.getSearchResult(ctx, c.searchEndpoint()+"?"+rawQuery)
+	recordStats(ctx, start, sres, err)
+	return sres, err
+}
+
+// SearchValues searches with v, e.g. values forwarded from an incoming
+// request, encoded as is instead of through a Search. Like
+// SearchRawQuery, the values are otherwise not validated.
+func (c *Client) SearchValues(ctx context.Context, v url.Values) (*SearchResult, error) {
+	return c.SearchRawQuery(ctx, v.Encode())
+}
+
+// getSearchResult fetches and decodes rawURL, serving
+// it from and storing it in the client's cache.
+func (c *Client) getSearchResult(ctx context.Context, rawURL string) (*SearchResult, error) {
+	if blob, ok := c.cache.lookup(rawURL); ok {
+		return c.decode(ctx, blob)
+	}
+	blob, _, err := c.fetch(ctx, rawURL)
 	if err != nil {
 		return nil, err
 	}
+	sres, err := c.decode(ctx, blob)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.store(rawURL, blob)
+	return sres, nil
+}
+
+// Ping checks that the search endpoint is reachable and healthy, e.g.
+// for readiness probes, with the smallest possible search. It bypasses
+// the client's cache and returns nil on success, or else the error,
+// such as a *NetworkError or *APIError, that a search would get.
+func (c *Client) Ping(ctx context.Context) error {
+	ctx, cancel := c.withDefaultTimeout(ctx)
+	defer cancel()
+
+	_, _, err := c.fetch(ctx, c.searchEndpoint()+"?term=a&limit=1")
+	return err
+}
+
+// SearchMany runs base once per term, with at most concurrency searches
+// in flight at a time, and returns their results in the order of terms.
+// The first failed search cancels the rest and its error is returned.
+func (c *Client) SearchMany(ctx context.Context, base *Search, terms []string, concurrency int) ([]*SearchResult, error) {
+	if base == nil {
+		return nil, errNilSearch
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	results := make([]*SearchResult, len(terms))
+	sem := make(chan bool, concurrency)
+
+spawn:
+	for i, term := range terms {
+		select {
+		case sem <- true:
+		case <-ctx.Done():
+			break spawn
+		}
+
+		wg.Add(1)
+		go func(i int, term string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			s := base.Clone()
+			s.Term = term
+			s.Terms = nil
+			sres, err := c.Search(ctx, s)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			results[i] = sres
+		}(i, term)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// HasMatches reports whether s matches anything, while asking the API
+// for only a single result. The API can't tell how many results there
+// are in total, since its resultCount is the number of results in the
+// response, so this is all that a single request can answer.
+func (c *Client) HasMatches(ctx context.Context, s *Search) (bool, error) {
+	if s == nil {
+		return false, errNilSearch
+	}
+	one := s.Clone()
+	one.Limit = 1
+	sres, err := c.Search(ctx, one)
+	if errors.Is(err, ErrNoResults) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return len(sres.Results) > 0, nil
+}
+
+// maxSearchLimit is the largest limit that the search endpoint honors.
+const maxSearchLimit = 200
+
+// SearchAll pages through the results of s, using s.Limit (or the
+// API's maximum if unset) as the page size, and returns up to max
+// results in total. It stops early once the API runs out of results.
+func (c *Client) SearchAll(ctx context.Context, s *Search, max int) ([]*Result, error) {
+	if s == nil {
+		return nil, errNilSearch
+	}
+	pageSize := int(s.Limit)
+	if pageSize <= 0 || pageSize > maxSearchLimit {
+		pageSize = maxSearchLimit
+	}
+
+	var all []*Result
+	for len(all) < max {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		limit := pageSize
+		if remaining := max - len(all); remaining < limit {
+			limit = remaining
+		}
+		page := s.Clone()
+		page.Limit = uint(limit)
+		page.Offset = s.Offset + uint(len(all))
+		sres, err := c.Search(ctx, page)
+		if errors.Is(err, ErrNoResults) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, sres.Results...)
+		if len(sres.Results) < limit {
+			break
+		}
+	}
+	return all, nil
+}
+
+// fetch gets the API response at rawURL, sharing the outcome with any
+// identical requests in flight if the client was created WithSingleflight.
+// Responses that are HTML pages rather than JSON fail with an *APIError.
+func (c *Client) fetch(ctx context.Context, rawURL string) ([]byte, *http.Response, error) {
+	return c.flights.do(c.flightKey(ctx, rawURL), func() ([]byte, *http.Response, error) {
+		blob, res, err := c.get(ctx, rawURL)
+		if err == nil {
+			err = htmlError(res, blob)
+		}
+		return blob, res, err
+	})
+}
+
+// flightKey identifies the request for rawURL that the client sends
+// with ctx by its URL and headers, so that only identical requests are
+// collapsed and no caller gets a response to, e.g., another caller's
+// X-Request-ID.
+func (c *Client) flightKey(ctx context.Context, rawURL string) string {
+	if c.flights == nil {
+		return rawURL
+	}
+	req, err := c.newRequest(ctx, rawURL)
+	if err != nil {
+		return rawURL
+	}
+	var key strings.Builder
+	key.WriteString(rawURL + "\n")
+	req.Header.Write(&key)
+	return key.String()
+}
+
+// get fetches rawURL and returns its body along with the response,
+// whose body has been drained and closed by the time get returns.
+func (c *Client) get(ctx context.Context, rawURL string) ([]byte, *http.Response, error) {
+	cancel := func() {}
+	if c.bodyReadTimeout > 0 {
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+	}
+
+	req, err := c.newRequest(ctx, rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	validated := c.validators.prepare(rawURL, req)
+	res, err := c.send(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
 	defer res.Body.Close()
 
+	if validated != nil && res.StatusCode == http.StatusNotModified {
+		return validated.blob, res, nil
+	}
+
+	// Once the headers are in, the body has to
+	// be read within the body read timeout.
+	var timedOut int32
+	if c.bodyReadTimeout > 0 {
+		timer := time.AfterFunc(c.bodyReadTimeout, func() {
+			atomic.StoreInt32(&timedOut, 1)
+			cancel()
+		})
+		defer timer.Stop()
+	}
+
 	if !statusOK(res.StatusCode) {
-		return nil, fmt.Errorf("status: %s", res.Status)
+		return nil, res, responseError(res)
 	}
 
-	blob, err := io.ReadAll(res.Body)
+	blob, err := c.readBody(res.Body)
+	if err != nil {
+		if atomic.LoadInt32(&timedOut) == 1 {
+			err = fmt.Errorf("%w after %v", ErrBodyReadTimeout, c.bodyReadTimeout)
+		}
+		if !errors.Is(err, ErrResponseTooLarge) {
+			err = &NetworkError{URL: rawURL, Err: err}
+		}
+		return nil, res, err
+	}
+	stats.Record(ctx, MeasureResponseBytes.M(int64(len(blob))))
+	c.validators.store(rawURL, res.Header, blob)
+	return blob, res, nil
+}
+
+// ErrBodyReadTimeout is returned when a response body takes longer
+// to read than the timeout set with WithBodyReadTimeout.
+var ErrBodyReadTimeout = errors.New("timed out reading response body")
+
+// ErrResponseTooLarge is returned when a response body exceeds
+// the limit set with WithMaxResponseBytes.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// readBody reads all of body, failing with ErrResponseTooLarge
+// if it is longer than the client's maximum response size.
+func (c *Client) readBody(body io.Reader) ([]byte, error) {
+	if c.maxResponseBytes <= 0 {
+		return io.ReadAll(body)
+	}
+	blob, err := io.ReadAll(io.LimitReader(body, c.maxResponseBytes+1))
 	if err != nil {
 		return nil, err
 	}
+	if int64(len(blob)) > c.maxResponseBytes {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrResponseTooLarge, c.maxResponseBytes)
+	}
+	return blob, nil
+}
 
-	fmt.Printf("Search: %q => %s\n", queryString, blob)
-	sres := new(SearchResult)
-	if err := json.Unmarshal(blob, sres); err != nil {
+// Close releases the resources that the client holds: it stops the
+// cache's background expiry and closes the idle connections of the
+// transport set WithTransport, if any. http.DefaultTransport, which
+// is shared with the rest of the process, is left alone. Clients
+// created WithCache should be closed once no longer needed. The
+// client must not be used after Close.
+func (c *Client) Close() error {
+	c.cache.close()
+
+	if idle, ok := c.transport.(interface{ CloseIdleConnections() }); ok {
+		idle.CloseIdleConnections()
+	}
+	return nil
+}
+
+// defaultMaxRedirects is how many redirects a request follows by default.
+const defaultMaxRedirects = 10
+
+// ErrTooManyRedirects is returned when a request is redirected
+// more times than the client allows, see WithMaxRedirects.
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+func (c *Client) httpClient() *http.Client {
+	maxRedirects := defaultMaxRedirects
+	switch {
+	case c.maxRedirects < 0:
+		maxRedirects = 0
+	case c.maxRedirects > 0:
+		maxRedirects = c.maxRedirects
+	}
+	base := c.transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if c.tap != nil {
+		base = &tapTransport{base: base, tap: c.tap}
+	}
+	var transport http.RoundTripper = &ochttp.Transport{Base: base}
+	if c.disableTracing {
+		transport = base
+	}
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, maxRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+// newRequest creates a GET request for rawURL with
+// the headers that the client sends with every request.
+func (c *Client) newRequest(ctx context.Context, rawURL string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
 		return nil, err
 	}
-	for _, res := range sres.Results {
-		if res.TrackViewURL == "" {
-			continue
-			panic("no trackViewURL")
+	for key, values := range c.header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	req.Header.Set("User-Agent", c.userAgentOrDefault())
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	if c.storefront != "" {
+		req.Header.Set("X-Apple-Store-Front", c.storefront)
+	}
+	if c.requestIDKey != nil {
+		if id := ctx.Value(c.requestIDKey); id != nil {
+			req.Header.Set("X-Request-ID", fmt.Sprint(id))
 		}
 	}
+	return req, nil
+}
+
+// do sends a GET request for rawURL configured as
+// per the client. The caller must close the response body.
+func (c *Client) do(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := c.newRequest(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(ctx, req)
+}
+
+// tapTransport calls tap after each of its round trips.
+type tapTransport struct {
+	base http.RoundTripper
+	tap  func(*http.Request, *http.Response)
+}
+
+func (tt *tapTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := tt.base.RoundTrip(req)
+	tt.tap(req, res)
+	return res, err
+}
+
+// send sends req, which must have been created by newRequest.
+// The caller must close the response body.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if c.observeRequest != nil {
+		c.observeRequest(req)
+	}
+
+	start := time.Now()
+	res, err := c.httpClient().Do(req)
+	duration := time.Since(start)
+
+	logger := c.loggerOrDefault()
+	switch {
+	case err != nil:
+		logger.WarnContext(ctx, "itunes: request failed",
+			"method", req.Method, "url", req.URL.String(), "duration", duration, "error", err)
+	case !statusOK(res.StatusCode) && res.StatusCode != http.StatusNotModified:
+		logger.WarnContext(ctx, "itunes: request failed",
+			"method", req.Method, "url", req.URL.String(), "status", res.StatusCode, "duration", duration)
+	default:
+		logger.DebugContext(ctx, "itunes: request",
+			"method", req.Method, "url", req.URL.String(), "status", res.StatusCode, "duration", duration)
+	}
+	if err != nil {
+		return nil, &NetworkError{URL: req.URL.String(), Err: err}
+	}
+
+	if err := decompress(res); err != nil {
+		res.Body.Close()
+		return nil, &NetworkError{URL: req.URL.String(), Err: err}
+	}
+	return res, nil
+}
+
+// decode decodes blob and applies the
+// client's post-processing to the results.
+func (c *Client) decode(ctx context.Context, blob []byte) (*SearchResult, error) {
+	if c.jsonpCallback != "" {
+		blob = stripJSONP(blob, c.jsonpCallback)
+	}
+	start := time.Now()
+	sres, err := decodeSearchResult(blob)
+	stats.Record(ctx, MeasureDecodeLatencyMs.M(sinceMs(start)))
+	if err != nil {
+		return nil, err
+	}
+	if c.strictResultCount && sres.IsTruncated() {
+		return nil, fmt.Errorf("%w: resultCount is %d but got %d results", ErrTruncated, sres.ResultCount, len(sres.Results))
+	}
+	for _, res := range sres.Results {
+		c.normalize(res)
+	}
+	return sres, nil
+}
+
+// normalize applies the client's normalization options to res.
+func (c *Client) normalize(res *Result) {
+	if c.normalizeURLs {
+		res.normalizeURLs()
+	}
+	if c.normalizeArtwork {
+		res.normalizeArtwork()
+	}
+}
 
+// decodeSearchResult decodes blob, treating an empty body, as
+// returned for example when looking up unknown ids, as no results.
+func decodeSearchResult(blob []byte) (*SearchResult, error) {
+	blob = bytes.TrimSpace(blob)
+	sres := new(SearchResult)
+	if len(blob) == 0 {
+		return sres, nil
+	}
+	if err := json.Unmarshal(blob, sres); err != nil {
+		return nil, &DecodeError{Err: err}
+	}
 	return sres, nil
 }
 
 // The goal of this function is to transform any struct
-// into a URL values map.
+// into a URL values map. Pointers encode as what they point
+// to, or not at all if nil, while nested structs and maps, which
+// have no query string form, are rejected with an error. Fields
+// tagged `query:"-"` are left out, for those encoded separately.
 // type a { A int;B []string;C []float32}{10, ["a","b"], [23.4,-10]} -> A=10&B=a,b&C=23.4,-10
 func valueToURLValues(ctx context.Context, ptrVal interface{}) (url.Values, error) {
-	_, span := trace.StartSpan(ctx, "itunes.valueToURLValues")
+	_, span := startSpan(ctx, "itunes.valueToURLValues")
 	defer span.End()
 
 	blob, err := json.Marshal(ptrVal)
@@ -103,30 +829,43 @@ func valueToURLValues(ctx context.Context, ptrVal interface{}) (url.Values, erro
 		return nil, err
 	}
 
+	// Decoding numbers as json.Number keeps them exactly as they were
+	// marshaled, so that e.g. 1000000 doesn't become "1e+06".
 	shadowMap := make(map[string]interface{})
-	if err := json.Unmarshal(blob, &shadowMap); err != nil {
+	dec := json.NewDecoder(bytes.NewReader(blob))
+	dec.UseNumber()
+	if err := dec.Decode(&shadowMap); err != nil {
 		return nil, err
 	}
+	for _, key := range queryOmittedKeys(ptrVal) {
+		delete(shadowMap, key)
+	}
 
+	// url.Values.Encode sorts by key and each key's values keep their
+	// order in ptrVal, so identical inputs always encode identically.
 	outValues := url.Values{}
 	for key, value := range shadowMap {
 		rv := reflect.ValueOf(value)
 		switch rv.Kind() {
+		case reflect.Invalid:
+			continue
+		case reflect.Map:
+			return nil, fmt.Errorf("cannot encode %q: nested objects have no query string form", key)
 		default:
-			if value == reflect.Invalid {
-				continue
-			}
 			str := fmt.Sprintf("%v", value)
 			if str != "" {
 				outValues[key] = []string{str}
 			}
-		case reflect.Array:
+		case reflect.Array, reflect.Slice:
 			var outL []string
 			for i, n := 0, rv.Len(); i < n; i++ {
 				ithItem := rv.Index(i)
-				if ithItem.Kind() == reflect.Invalid {
+				if ithItem.IsNil() {
 					continue
 				}
+				if kind := ithItem.Elem().Kind(); kind == reflect.Map || kind == reflect.Slice {
+					return nil, fmt.Errorf("cannot encode %q: nested %v values have no query string form", key, kind)
+				}
 				str := fmt.Sprintf("%v", ithItem.Interface())
 				if str != "" {
 					outL = append(outL, str)
@@ -141,6 +880,31 @@ func valueToURLValues(ctx context.Context, ptrVal interface{}) (url.Values, erro
 	return outValues, nil
 }
 
+// queryOmittedKeys returns the JSON keys of the fields
+// of the struct that v points to that are tagged `query:"-"`.
+func queryOmittedKeys(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("query") != "-" {
+			continue
+		}
+		key := strings.Split(field.Tag.Get("json"), ",")[0]
+		if key == "" {
+			key = field.Name
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 func statusOK(code int) bool { return code >= 200 && code <= 299 }
 
 type SearchResult struct {
@@ -149,74 +913,443 @@ type SearchResult struct {
 }
 
 type Result struct {
-	Kind              string  `json:"kind"`
-	TrackId           uint64  `json:"trackId"`
-	CollectionId      uint64  `json:"collectionId"`
-	ArtistName        string  `json:"artistName"`
-	LongDescription   string  `json:"longDescription"`
-	ShortDescription  string  `json:"shortDescription"`
-	TrackPrice        float64 `json:"trackPrice"`
-	Country           string  `json:"country"`
-	Currency          string  `josn:"currency"`
-	CollectionName    string  `json:"collectionName"`
-	PrimaryGenreName  string  `json:"primaryGenreName"`
-	TrackName         string  `json:"trackName"`
-	TrackCensoredName string  `json:"trackCensoredName"`
-	TrackNumber       uint    `json:"trackNumber"`
-	TrackTimeMillis   uint64  `json:"trackTimeMillis"`
-	TrackViewURL      string  `json:"trackViewUrl"`
-	CollectionPrice   float64 `json:"collectionPrice"`
-	CollectionViewURL string  `json:"collectionViewUrl"`
-	ArtistViewURL     string  `json:"artistViewUrl"`
-	PreviewURL        string  `json:"previewUrl"`
-	Streamable        bool    `json:"isStreamable"`
-	ArtworkURL100Px   string  `json:"artworkUrl100"`
-	ArtworkURL60Px    string  `json:"artworkUrl60"`
-	ArtworkURL30Px    string  `json:"artworkUrl30"`
-}
-
-func (c *Client) SearchById(ctx context.Context, id string) (*SearchResult, error) {
-	qURL := fmt.Sprintf("https://itunes.apple.com/lookup?id=%s", id)
-	req, err := http.NewRequestWithContext(ctx, "GET", qURL, nil)
+	WrapperType            WrapperType  `json:"wrapperType"`
+	CollectionType         string       `json:"collectionType"`
+	Kind                   Kind         `json:"kind"`
+	TrackId                uint64       `json:"trackId"`
+	CollectionId           uint64       `json:"collectionId"`
+	ArtistId               uint64       `json:"artistId"`
+	ArtistName             string       `json:"artistName"`
+	CollectionArtistId     uint64       `json:"collectionArtistId"`
+	CollectionArtistName   string       `json:"collectionArtistName"`
+	LongDescription        string       `json:"longDescription"`
+	ShortDescription       string       `json:"shortDescription"`
+	TrackPrice             float64      `json:"trackPrice"`
+	Country                string       `json:"country"`
+	Currency               string       `json:"currency"`
+	CollectionName         string       `json:"collectionName"`
+	CollectionCensoredName string       `json:"collectionCensoredName"`
+	PrimaryGenreName       string       `json:"primaryGenreName"`
+	GenreIDs               []string     `json:"genreIds"`
+	Genres                 []string     `json:"genres"`
+	TrackName              string       `json:"trackName"`
+	TrackCensoredName      string       `json:"trackCensoredName"`
+	TrackNumber            uint         `json:"trackNumber"`
+	TrackCount             uint         `json:"trackCount"`
+	DiscNumber             uint         `json:"discNumber"`
+	DiscCount              uint         `json:"discCount"`
+	TrackTimeMillis        uint64       `json:"trackTimeMillis"`
+	ReleaseDate            time.Time    `json:"releaseDate"`
+	TrackViewURL           string       `json:"trackViewUrl"`
+	CollectionPrice        float64      `json:"collectionPrice"`
+	CollectionViewURL      string       `json:"collectionViewUrl"`
+	ArtistViewURL          string       `json:"artistViewUrl"`
+	PreviewURL             string       `json:"previewUrl"`
+	FeedURL                string       `json:"feedUrl"`
+	Streamable             bool         `json:"isStreamable"`
+	TrackExplicitness      Explicitness `json:"trackExplicitness"`
+	CollectionExplicitness Explicitness `json:"collectionExplicitness"`
+	ContentAdvisoryRating  string       `json:"contentAdvisoryRating"`
+	ArtworkURL100Px        string       `json:"artworkUrl100"`
+	ArtworkURL60Px         string       `json:"artworkUrl60"`
+	ArtworkURL30Px         string       `json:"artworkUrl30"`
+}
+
+// SearchById looks up id. Any opts override the client's
+// own options for this call only, like they do for Search.
+func (c *Client) SearchById(ctx context.Context, id string, opts ...CallOption) (*SearchResult, error) {
+	return c.withCallOptions(opts).LookupWithParams(ctx, id, nil)
+}
+
+// LookupParams are the optional parameters of a lookup.
+type LookupParams struct {
+	// Entity restricts the results related to
+	// the looked up id, e.g. an artist's albums.
+	Entity Entity
+	Limit  uint
+
+	// SortRecent sorts the related results by
+	// release date, most recent first.
+	SortRecent bool
+
+	// Country selects the store to look up in, e.g. "GB".
+	// The API defaults to the US store.
+	Country Country
+}
+
+func (lp *LookupParams) urlValues(id string) url.Values {
+	values := url.Values{"id": {id}}
+	if lp == nil {
+		return values
+	}
+	if lp.Entity != "" {
+		values.Set("entity", string(lp.Entity))
+	}
+	if lp.Limit > 0 {
+		values.Set("limit", strconv.FormatUint(uint64(lp.Limit), 10))
+	}
+	if lp.SortRecent {
+		values.Set("sort", "recent")
+	}
+	if lp.Country != "" {
+		values.Set("country", string(lp.Country))
+	}
+	return values
+}
+
+// LookupWithParams looks up id, encoding the optional
+// params into the request. A nil params is valid.
+func (c *Client) LookupWithParams(ctx context.Context, id string, params *LookupParams) (*SearchResult, error) {
+	ctx, cancel := c.withDefaultTimeout(ctx)
+	defer cancel()
+
+	start := time.Now()
+	sres, _, err := c.lookupRaw(ctx, id, params)
+	recordStats(ctx, start, sres, err)
+	return sres, err
+}
+
+func (c *Client) lookupRaw(ctx context.Context, id string, params *LookupParams) (*SearchResult, *http.Response, error) {
+	qURL := c.lookupRequestURL(ctx, id, params)
+	if blob, ok := c.cache.lookup(qURL); ok {
+		sres, err := c.decode(ctx, blob)
+		return sres, nil, err
+	}
+	blob, res, err := c.fetch(ctx, qURL)
+	if err != nil {
+		return nil, res, err
+	}
+	sres, err := c.decode(ctx, blob)
+	if err != nil {
+		return nil, res, err
+	}
+	c.cache.store(qURL, blob)
+	return sres, res, nil
+}
+
+// ErrTruncated is returned by clients created WithStrictResultCount
+// when a response holds fewer results than its resultCount.
+var ErrTruncated = errors.New("truncated results")
+
+// ErrNoResults is returned by searches that found nothing
+// by clients created WithErrorOnEmpty.
+var ErrNoResults = errors.New("no results")
+
+// ErrNotFound is returned by LookupOne when the id matches nothing.
+var ErrNotFound = errors.New("not found")
+
+// LookupOne looks up id and returns its single result. It returns
+// ErrNotFound if there are no results and an error if there are many.
+func (c *Client) LookupOne(ctx context.Context, id string) (*Result, error) {
+	sres, err := c.SearchById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	res, err := http.DefaultClient.Do(req)
+	switch n := len(sres.Results); n {
+	case 0:
+		return nil, ErrNotFound
+	case 1:
+		return sres.Results[0], nil
+	default:
+		return nil, fmt.Errorf("lookup of %q returned %d results, want 1", id, n)
+	}
+}
+
+// lookupChunkSize is the most ids LookupByIDs sends in a single request.
+const lookupChunkSize = 50
+
+// LookupByIDs resolves ids using as few lookup requests as possible,
+// joining them with commas in groups of at most lookupChunkSize, and
+// aggregates the results of all the groups in order.
+func (c *Client) LookupByIDs(ctx context.Context, ids []string) (*SearchResult, error) {
+	agg := new(SearchResult)
+	for len(ids) > 0 {
+		n := lookupChunkSize
+		if n > len(ids) {
+			n = len(ids)
+		}
+		chunk := ids[:n]
+		ids = ids[n:]
+
+		sres, err := c.SearchById(ctx, strings.Join(chunk, ","))
+		if err != nil {
+			return nil, err
+		}
+		agg.ResultCount += sres.ResultCount
+		agg.Results = append(agg.Results, sres.Results...)
+	}
+	return agg, nil
+}
+
+// LookupMany looks up all the ids and returns a map from each input
+// id to the result it resolved to. Ids for which the API returned no
+// result are present in the map with a nil value, so callers can tell
+// them apart from ids never requested.
+func (c *Client) LookupMany(ctx context.Context, ids []string) (map[string]*Result, error) {
+	if len(ids) == 0 {
+		return map[string]*Result{}, nil
+	}
+	sres, err := c.LookupByIDs(ctx, ids)
 	if err != nil {
 		return nil, err
 	}
-	if res.StatusCode/100 != 2 {
-		return nil, fmt.Errorf("failed with %q", res.Status)
+
+	byId := make(map[string]*Result)
+	for _, res := range sres.Results {
+		id := res.id()
+		if _, seen := byId[id]; id != "" && !seen {
+			byId[id] = res
+		}
 	}
-	blob, err := io.ReadAll(res.Body)
-	res.Body.Close()
-	blob = bytes.TrimSpace(blob)
 
-	sres := new(SearchResult)
-	if err := json.Unmarshal(blob, sres); err != nil {
+	resolved := make(map[string]*Result, len(ids))
+	for _, id := range ids {
+		resolved[id] = byId[id]
+	}
+	return resolved, nil
+}
+
+// maxLookupLimit is the largest limit that the lookup endpoint honors.
+const maxLookupLimit = 200
+
+// ArtistDiscography looks up the works of the artist with artistID,
+// e.g. their albums for EntityAlbum, which is also the default,
+// most recent first and as many as the API returns for a lookup. The
+// artist itself, which the API sends first, is left out of the results.
+func (c *Client) ArtistDiscography(ctx context.Context, artistID string, entity Entity) (*SearchResult, error) {
+	if entity == "" {
+		entity = EntityAlbum
+	}
+	params := &LookupParams{Entity: entity, Limit: maxLookupLimit, SortRecent: true}
+	sres, err := c.LookupWithParams(ctx, artistID, params)
+	if err != nil {
 		return nil, err
 	}
-	return sres, nil
+
+	works := new(SearchResult)
+	for _, res := range sres.Results {
+		if res.WrapperType != WrapperArtist {
+			works.Results = append(works.Results, res)
+		}
+	}
+	works.ResultCount = uint64(len(works.Results))
+	return works, nil
+}
+
+// id returns the identifier that the lookup endpoint
+// would have been queried with to produce this result.
+func (r *Result) id() string {
+	var id uint64
+	switch r.WrapperType {
+	case WrapperTrack:
+		id = r.TrackId
+	case WrapperCollection:
+		id = r.CollectionId
+	case WrapperArtist:
+		id = r.ArtistId
+	default:
+		switch {
+		case r.TrackId != 0:
+			id = r.TrackId
+		case r.CollectionId != 0:
+			id = r.CollectionId
+		default:
+			id = r.ArtistId
+		}
+	}
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatUint(id, 10)
 }
 
 type Search struct {
-	Term            string    `json:"term"`
-	Country         Country   `json:"country"`
-	Media           Media     `json:"media"`
-	Entity          Entity    `json:"entity"`
-	Attribute       Attribute `json:"attribute"`
-	Language        Language  `json:"lang"`
-	Limit           uint      `json:"limit"`
-	Version         string    `json:"version"`
-	ExplicitContent bool      `json:"explicit"`
-	Id              string    `json:"id"`
+	Term            string         `json:"term,omitempty"`
+	Country         Country        `json:"country,omitempty"`
+	Media           Media          `json:"media,omitempty"`
+	Entity          Entity         `json:"entity,omitempty"`
+	Attribute       Attribute      `json:"attribute,omitempty"`
+	GenreID         string         `json:"genreId,omitempty"`
+	Language        Language       `json:"lang,omitempty"`
+	Limit           uint           `json:"limit,omitempty"`
+	Offset          uint           `json:"offset,omitempty"`
+	Version         Version        `json:"version,omitempty"`
+	ExplicitContent ExplicitFilter `json:"explicit,omitempty"`
+
+	// Id, if set, turns the search into a lookup of the id, which
+	// only takes Country, Entity, Entities and Limit of the other fields,
+	// unless the client was created WithLookupOnID(false).
+	Id string `json:"id,omitempty"`
+
+	// Terms holds further words to search for alongside Term. All
+	// of them are sent as a single space-separated term, which the
+	// API matches as separate words.
+	Terms []string `json:"terms,omitempty" query:"-"`
+
+	// Entities holds further entities to search for alongside
+	// Entity. All of them are sent as a comma-separated list.
+	Entities []Entity `json:"entities,omitempty" query:"-"`
+
+	// Extra holds query parameters that Search doesn't model, such
+	// as ones added to the API later. Parameters set by the other
+	// fields take precedence.
+	Extra url.Values `json:"extra,omitempty" query:"-"`
 }
 
+// Clone returns a deep copy of s, which can be
+// modified without affecting s, or nil if s is nil.
+func (s *Search) Clone() *Search {
+	if s == nil {
+		return nil
+	}
+	clone := *s
+	if s.Terms != nil {
+		clone.Terms = append([]string(nil), s.Terms...)
+	}
+	if s.Entities != nil {
+		clone.Entities = append([]Entity(nil), s.Entities...)
+	}
+	if s.Extra != nil {
+		clone.Extra = make(url.Values, len(s.Extra))
+		for key, values := range s.Extra {
+			clone.Extra[key] = append([]string(nil), values...)
+		}
+	}
+	return &clone
+}
+
+// EncodeQuery returns the query string that a search for s sends,
+// without making any request.
+func (s *Search) EncodeQuery(ctx context.Context) (string, error) {
+	urlValues, err := valueToURLValues(ctx, s)
+	if err != nil {
+		return "", err
+	}
+	if term := s.term(); term != "" {
+		urlValues.Set("term", term)
+	}
+	if entity := s.entityList(); entity != "" {
+		urlValues.Set("entity", entity)
+	}
+	for key, values := range s.Extra {
+		if _, ok := urlValues[key]; !ok {
+			urlValues[key] = append([]string(nil), values...)
+		}
+	}
+	return urlValues.Encode(), nil
+}
+
+// term joins Term and Terms into the
+// space-separated term that the API expects.
+func (s *Search) term() string {
+	var words []string
+	if s.Term != "" {
+		words = append(words, s.Term)
+	}
+	for _, word := range s.Terms {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// entityList joins Entity and Entities into the
+// comma-separated list that the API expects.
+func (s *Search) entityList() string {
+	var entities []string
+	if s.Entity != "" {
+		entities = append(entities, string(s.Entity))
+	}
+	for _, entity := range s.Entities {
+		if entity != "" {
+			entities = append(entities, string(entity))
+		}
+	}
+	return strings.Join(entities, ",")
+}
+
+// lookupParams returns the parameters of s that
+// carry over to a lookup of its Id, if any.
+func (s *Search) lookupParams() *LookupParams {
+	entity := s.entityList()
+	if entity == "" && s.Limit == 0 && s.Country == "" {
+		return nil
+	}
+	return &LookupParams{Entity: Entity(entity), Limit: s.Limit, Country: s.Country}
+}
+
+// Version is the version of the result keys that a search gets back.
+// Version2, the API's default, returns the current result keys, while
+// Version1 returns the keys of the original, legacy response format.
+type Version string
+
+const (
+	Version1 Version = "1"
+	Version2 Version = "2"
+)
+
 type Country string
 type Language string
 type Media string
 type Attribute string
 
+// AttributeArtistTerm matches the search term against artist names only.
+const AttributeArtistTerm Attribute = "artistTerm"
+
+// WrapperType tells how a Result should be interpreted,
+// for example as a track or as an artist.
+type WrapperType string
+
+const (
+	WrapperTrack      WrapperType = "track"
+	WrapperCollection WrapperType = "collection"
+	WrapperArtist     WrapperType = "artist"
+)
+
+// Kind is the type of content that a Result describes.
+type Kind string
+
+const (
+	KindAlbum              Kind = "album"
+	KindArtist             Kind = "artist"
+	KindBook               Kind = "book"
+	KindCoachedAudio       Kind = "coached-audio"
+	KindEBook              Kind = "ebook"
+	KindFeatureMovie       Kind = "feature-movie"
+	KindInteractiveBooklet Kind = "interactive-booklet"
+	KindMacSoftware        Kind = "mac-software"
+	KindMusicVideo         Kind = "music-video"
+	KindPDFPodcast         Kind = "pdf podcast"
+	KindPodcast            Kind = "podcast"
+	KindPodcastEpisode     Kind = "podcast-episode"
+	KindSoftware           Kind = "software"
+	KindSoftwarePackage    Kind = "software-package"
+	KindSong               Kind = "song"
+	KindTVEpisode          Kind = "tv-episode"
+)
+
+// Explicitness describes whether a track or
+// collection contains explicit content.
+type Explicitness string
+
+const (
+	Explicit    Explicitness = "explicit"
+	Cleaned     Explicitness = "cleaned"
+	NotExplicit Explicitness = "notExplicit"
+)
+
+// ExplicitFilter is whether a search includes explicit content.
+// ExplicitUnset leaves the parameter out of the query, so that
+// the API applies its own default.
+type ExplicitFilter string
+
+const (
+	ExplicitUnset ExplicitFilter = ""
+	ExplicitYes   ExplicitFilter = "Yes"
+	ExplicitNo    ExplicitFilter = "No"
+)
+
 type Entity string
 
 const (
@@ -227,6 +1360,10 @@ const (
 	EntityMusic           Entity = "music"
 	EntityMusicVideo      Entity = "musicVideo"
 	EntityMusicArtist     Entity = "musicArtist"
+	EntityMusicTrack      Entity = "musicTrack"
+	EntityAlbum           Entity = "album"
+	EntityMix             Entity = "mix"
+	EntitySong            Entity = "song"
 	EntityAudioBook       Entity = "audiobook"
 	EntityAudioBookAuthor Entity = "audiobookAuthor"
 	EntityShortFilm       Entity = "shortFilm"
@@ -239,5 +1376,10 @@ const (
 	EntityMacSoftware     Entity = "macSoftware"
 	EntityEBook           Entity = "ebook"
 	EntityAll             Entity = "all"
+	EntityAllArtist       Entity = "allArtist"
 	EntityAllTrack        Entity = "allTrack"
+
+	// EntityRingtone is accepted by the API for music
+	// searches, although it is no longer documented.
+	EntityRingtone Entity = "ringtone"
 )
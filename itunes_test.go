@@ -0,0 +1,1778 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResultWrapperType(t *testing.T) {
+	blob := []byte(`{
+		"resultCount": 2,
+		"results": [
+			{"wrapperType": "artist", "artistType": "Artist", "artistName": "Jack Johnson"},
+			{"wrapperType": "track", "kind": "song", "collectionType": "Album", "trackName": "Upside Down"}
+		]
+	}`)
+
+	sres := new(SearchResult)
+	if err := json.Unmarshal(blob, sres); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if g, w := len(sres.Results), 2; g != w {
+		t.Fatalf("len(Results)=%d want %d", g, w)
+	}
+
+	artist, track := sres.Results[0], sres.Results[1]
+	if g, w := artist.WrapperType, WrapperArtist; g != w {
+		t.Errorf("artist.WrapperType=%q want %q", g, w)
+	}
+	if g, w := track.WrapperType, WrapperTrack; g != w {
+		t.Errorf("track.WrapperType=%q want %q", g, w)
+	}
+	if g, w := track.CollectionType, "Album"; g != w {
+		t.Errorf("track.CollectionType=%q want %q", g, w)
+	}
+}
+
+func TestLookupMany(t *testing.T) {
+	var gotQuery string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("id")
+		// The API does not preserve the order of the requested ids.
+		fmt.Fprint(w, `{
+			"resultCount": 3,
+			"results": [
+				{"wrapperType": "collection", "collectionId": 200, "artistId": 300, "collectionName": "Album"},
+				{"wrapperType": "artist", "artistId": 300, "artistName": "Artist"},
+				{"wrapperType": "track", "trackId": 100, "collectionId": 200, "artistId": 300, "trackName": "Track"}
+			]
+		}`)
+	}))
+	defer cst.Close()
+
+	client := &Client{lookupURL: cst.URL}
+	ids := []string{"100", "200", "300", "999"}
+	resolved, err := client.LookupMany(context.Background(), ids)
+	if err != nil {
+		t.Fatalf("LookupMany: %v", err)
+	}
+	if g, w := gotQuery, "100,200,300,999"; g != w {
+		t.Errorf("id query=%q want %q", g, w)
+	}
+	if g, w := len(resolved), len(ids); g != w {
+		t.Fatalf("len(resolved)=%d want %d", g, w)
+	}
+
+	if res := resolved["100"]; res == nil || res.TrackName != "Track" {
+		t.Errorf("100 resolved to %+v, want the track", res)
+	}
+	if res := resolved["200"]; res == nil || res.CollectionName != "Album" {
+		t.Errorf("200 resolved to %+v, want the collection", res)
+	}
+	if res := resolved["300"]; res == nil || res.ArtistName != "Artist" {
+		t.Errorf("300 resolved to %+v, want the artist", res)
+	}
+	res, ok := resolved["999"]
+	if !ok {
+		t.Error("999 is absent from the map, want it marked as missing")
+	}
+	if res != nil {
+		t.Errorf("999 resolved to %+v, want nil", res)
+	}
+}
+
+func TestPackageLevelFindAndLookup(t *testing.T) {
+	var paths []string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		fmt.Fprint(w, `{"resultCount": 1, "results": [{"trackId": 1, "trackName": "One"}]}`)
+	}))
+	defer cst.Close()
+
+	prev := DefaultClient
+	DefaultClient = &Client{searchURL: cst.URL + "/search", lookupURL: cst.URL + "/lookup"}
+	defer func() { DefaultClient = prev }()
+
+	ctx := context.Background()
+	sres, err := Find(ctx, &Search{Term: "one"})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if g, w := sres.ResultCount, uint64(1); g != w {
+		t.Errorf("Find: ResultCount=%d want %d", g, w)
+	}
+	if _, err := Lookup(ctx, "1"); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	want := []string{"/search", "/lookup"}
+	if len(paths) != len(want) || paths[0] != want[0] || paths[1] != want[1] {
+		t.Errorf("paths=%q want %q", paths, want)
+	}
+}
+
+func TestWithDefaultTimeout(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(150 * time.Millisecond):
+		}
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	client := NewClient(WithDefaultTimeout(30 * time.Millisecond))
+	client.searchURL = cst.URL
+
+	start := time.Now()
+	_, err := client.Search(context.Background(), &Search{Term: "slow"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err=%v want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed >= 150*time.Millisecond {
+		t.Errorf("request took %s, want it cancelled at the default timeout", elapsed)
+	}
+
+	// An explicit deadline on the caller's context takes precedence.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.Search(ctx, &Search{Term: "slow"}); err != nil {
+		t.Errorf("with explicit deadline: %v", err)
+	}
+}
+
+func TestPerCallOptions(t *testing.T) {
+	var headers []http.Header
+	var mu sync.Mutex
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers = append(headers, r.Header)
+		mu.Unlock()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(150 * time.Millisecond):
+		}
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	client := NewClient(WithDefaultTimeout(5*time.Second), WithHeader("X-Client", "shared"))
+	client.searchURL = cst.URL
+	client.lookupURL = cst.URL
+
+	ctx := context.Background()
+	start := time.Now()
+	_, err := client.Search(ctx, &Search{Term: "slow"}, WithCallTimeout(30*time.Millisecond), WithCallHeader("X-Call", "1"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err=%v want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed >= 150*time.Millisecond {
+		t.Errorf("request took %s, want it cancelled at the per-call timeout", elapsed)
+	}
+	if _, err := client.SearchById(ctx, "1", WithCallTimeout(30*time.Millisecond)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SearchById: err=%v want %v", err, context.DeadlineExceeded)
+	}
+
+	// The client itself is unaffected.
+	if _, err := client.Search(ctx, &Search{Term: "slow"}); err != nil {
+		t.Errorf("without per-call options: %v", err)
+	}
+	if g, w := client.defaultTimeout, 5*time.Second; g != w {
+		t.Errorf("defaultTimeout=%v want %v", g, w)
+	}
+	if g := client.header.Get("X-Call"); g != "" {
+		t.Errorf("X-Call=%q leaked into the client", g)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if g, w := len(headers), 3; g != w {
+		t.Fatalf("backend got %d requests want %d", g, w)
+	}
+	for i, want := range []string{"1", "", ""} {
+		if g := headers[i].Get("X-Call"); g != want {
+			t.Errorf("#%d: X-Call=%q want %q", i, g, want)
+		}
+		if g, w := headers[i].Get("X-Client"), "shared"; g != w {
+			t.Errorf("#%d: X-Client=%q want %q", i, g, w)
+		}
+	}
+}
+
+func TestLookupByIDs(t *testing.T) {
+	var queries []string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idsCSV := r.URL.Query().Get("id")
+		queries = append(queries, idsCSV)
+		ids := strings.Split(idsCSV, ",")
+		sres := &SearchResult{ResultCount: uint64(len(ids))}
+		for _, id := range ids {
+			trackId, _ := strconv.ParseUint(id, 10, 64)
+			sres.Results = append(sres.Results, &Result{TrackId: trackId})
+		}
+		json.NewEncoder(w).Encode(sres)
+	}))
+	defer cst.Close()
+
+	var ids []string
+	for i := 1; i <= 2*lookupChunkSize+3; i++ {
+		ids = append(ids, strconv.Itoa(i))
+	}
+
+	client := &Client{lookupURL: cst.URL}
+	sres, err := client.LookupByIDs(context.Background(), ids)
+	if err != nil {
+		t.Fatalf("LookupByIDs: %v", err)
+	}
+
+	wantQueries := []string{
+		strings.Join(ids[:lookupChunkSize], ","),
+		strings.Join(ids[lookupChunkSize:2*lookupChunkSize], ","),
+		strings.Join(ids[2*lookupChunkSize:], ","),
+	}
+	if !reflect.DeepEqual(queries, wantQueries) {
+		t.Errorf("queries=%q\nwant %q", queries, wantQueries)
+	}
+	if g, w := sres.ResultCount, uint64(len(ids)); g != w {
+		t.Errorf("ResultCount=%d want %d", g, w)
+	}
+	if g, w := len(sres.Results), len(ids); g != w {
+		t.Fatalf("len(Results)=%d want %d", g, w)
+	}
+	for i, res := range sres.Results {
+		if g, w := res.TrackId, uint64(i+1); g != w {
+			t.Errorf("#%d: TrackId=%d want %d", i, g, w)
+		}
+	}
+}
+
+func TestLookupWithParams(t *testing.T) {
+	var gotQuery string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	client := &Client{lookupURL: cst.URL}
+	params := &LookupParams{Entity: EntityMusicVideo, SortRecent: true}
+	if _, err := client.LookupWithParams(context.Background(), "909253", params); err != nil {
+		t.Fatalf("LookupWithParams: %v", err)
+	}
+	if g, w := gotQuery, "entity=musicVideo&id=909253&sort=recent"; g != w {
+		t.Errorf("query=%q want %q", g, w)
+	}
+
+	params = &LookupParams{Entity: EntityMusicVideo, Limit: 5}
+	if _, err := client.LookupWithParams(context.Background(), "909253", params); err != nil {
+		t.Fatalf("LookupWithParams: %v", err)
+	}
+	if g, w := gotQuery, "entity=musicVideo&id=909253&limit=5"; g != w {
+		t.Errorf("query=%q want %q", g, w)
+	}
+}
+
+func TestSearchRaw(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=300")
+		w.Header().Set("X-Rate-Limit", "20")
+		w.WriteHeader(http.StatusNonAuthoritativeInfo)
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	client := &Client{searchURL: cst.URL}
+	sres, res, err := client.SearchRaw(context.Background(), &Search{Term: "nothing"})
+	if err != nil {
+		t.Fatalf("SearchRaw: %v", err)
+	}
+	if sres.ResultCount != 0 {
+		t.Errorf("ResultCount=%d want 0", sres.ResultCount)
+	}
+	if g, w := res.StatusCode, http.StatusNonAuthoritativeInfo; g != w {
+		t.Errorf("StatusCode=%d want %d", g, w)
+	}
+	if g, w := res.Header.Get("Cache-Control"), "max-age=300"; g != w {
+		t.Errorf("Cache-Control=%q want %q", g, w)
+	}
+	if g, w := res.Header.Get("X-Rate-Limit"), "20"; g != w {
+		t.Errorf("X-Rate-Limit=%q want %q", g, w)
+	}
+}
+
+func TestSearchOffset(t *testing.T) {
+	ctx := context.Background()
+	values, err := valueToURLValues(ctx, &Search{Term: "x", Offset: 50})
+	if err != nil {
+		t.Fatalf("valueToURLValues: %v", err)
+	}
+	if g, w := values.Get("offset"), "50"; g != w {
+		t.Errorf("offset=%q want %q", g, w)
+	}
+
+	values, err = valueToURLValues(ctx, &Search{Term: "x"})
+	if err != nil {
+		t.Fatalf("valueToURLValues: %v", err)
+	}
+	if _, ok := values["offset"]; ok {
+		t.Errorf("offset=%q present, want it omitted when zero", values.Get("offset"))
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	var userAgents []string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	for _, tt := range []struct {
+		client *Client
+		want   string
+	}{
+		{client: new(Client), want: defaultUserAgent},
+		{client: NewClient(WithUserAgent("jukebox/1.0")), want: "jukebox/1.0"},
+	} {
+		userAgents = nil
+		tt.client.searchURL = cst.URL
+		tt.client.lookupURL = cst.URL
+		if _, err := tt.client.Search(ctx, &Search{Term: "x"}); err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if _, err := tt.client.SearchById(ctx, "1"); err != nil {
+			t.Fatalf("SearchById: %v", err)
+		}
+		want := []string{tt.want, tt.want}
+		if !reflect.DeepEqual(userAgents, want) {
+			t.Errorf("User-Agents=%q want %q", userAgents, want)
+		}
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	page := "<html>" + strings.Repeat("Service Unavailable. ", 1000) + "</html>"
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("media") == "outage" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, page)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		if r.URL.Query().Get("media") == "plain" {
+			fmt.Fprint(w, "Bad Request\n")
+			return
+		}
+		fmt.Fprint(w, `{"errorMessage":"Invalid value(s) for key(s): [media]"}`)
+	}))
+	defer cst.Close()
+
+	client := &Client{searchURL: cst.URL}
+	tests := []struct {
+		media Media
+		want  string
+	}{
+		{media: "bogus", want: "status: 400 Bad Request: Invalid value(s) for key(s): [media]"},
+		{media: "plain", want: "status: 400 Bad Request: Bad Request"},
+		// Long bodies are quoted only up to maxSnippetBytes.
+		{media: "outage", want: "status: 503 Service Unavailable: " + page[:maxSnippetBytes]},
+	}
+	for _, tt := range tests {
+		_, err := client.Search(context.Background(), &Search{Term: "x", Media: tt.media})
+		if err == nil {
+			t.Errorf("media=%q: expected an error", tt.media)
+			continue
+		}
+		if g, w := err.Error(), tt.want; g != w {
+			t.Errorf("media=%q: err=%q want %q", tt.media, g, w)
+		}
+	}
+
+	// The full body is still kept on the error.
+	_, err := client.Search(context.Background(), &Search{Term: "x", Media: "outage"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err=%v (%T) want an *APIError", err, err)
+	}
+	if g, w := string(apiErr.Body), page; g != w {
+		t.Errorf("len(Body)=%d want %d", len(g), len(w))
+	}
+}
+
+func TestSearchEncodeQuery(t *testing.T) {
+	s := &Search{
+		Term:            "jack johnson",
+		Country:         "US",
+		Media:           "music",
+		Entity:          EntityMusicVideo,
+		Attribute:       "artistTerm",
+		Language:        "en_us",
+		Limit:           25,
+		Offset:          50,
+		Version:         "2",
+		ExplicitContent: ExplicitYes,
+	}
+	got, err := s.EncodeQuery(context.Background())
+	if err != nil {
+		t.Fatalf("EncodeQuery: %v", err)
+	}
+	want := "attribute=artistTerm&country=US&entity=musicVideo&explicit=Yes&lang=en_us" +
+		"&limit=25&media=music&offset=50&term=jack+johnson&version=2"
+	if got != want {
+		t.Errorf("query=%q\nwant  %q", got, want)
+	}
+}
+
+func TestEncodeQueryExplicit(t *testing.T) {
+	tests := []struct {
+		explicit ExplicitFilter
+		want     string
+	}{
+		{ExplicitUnset, "term=x"},
+		{ExplicitYes, "explicit=Yes&term=x"},
+		{ExplicitNo, "explicit=No&term=x"},
+	}
+	for i, tt := range tests {
+		got, err := (&Search{Term: "x", ExplicitContent: tt.explicit}).EncodeQuery(context.Background())
+		if err != nil {
+			t.Errorf("#%d: EncodeQuery: %v", i, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("#%d: query=%q want %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeQueryIsDeterministic(t *testing.T) {
+	ctx := context.Background()
+	s := &Search{
+		Term:      "daft punk",
+		Country:   "FR",
+		Media:     "music",
+		Entity:    EntityMusicVideo,
+		Attribute: "artistTerm",
+		Limit:     10,
+	}
+	first, err := s.EncodeQuery(ctx)
+	if err != nil {
+		t.Fatalf("EncodeQuery: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		got, err := s.EncodeQuery(ctx)
+		if err != nil {
+			t.Fatalf("#%d: EncodeQuery: %v", i, err)
+		}
+		if got != first {
+			t.Fatalf("#%d: query=%q want %q", i, got, first)
+		}
+	}
+
+	// Multiple values for a key keep their given order.
+	multi := &struct {
+		Tags []string `json:"tags"`
+	}{Tags: []string{"z", "a", "m"}}
+	values, err := valueToURLValues(ctx, multi)
+	if err != nil {
+		t.Fatalf("valueToURLValues: %v", err)
+	}
+	if g, w := values.Encode(), "tags=z&tags=a&tags=m"; g != w {
+		t.Errorf("multi-valued query=%q want %q", g, w)
+	}
+}
+
+func TestValueToURLValuesNested(t *testing.T) {
+	ctx := context.Background()
+	type price struct {
+		Min float64 `json:"min"`
+		Max float64 `json:"max"`
+	}
+
+	limit := uint(5)
+	withPointers := &struct {
+		Term  string `json:"term"`
+		Limit *uint  `json:"limit"`
+		Media *Media `json:"media"`
+	}{Term: "x", Limit: &limit}
+	values, err := valueToURLValues(ctx, withPointers)
+	if err != nil {
+		t.Fatalf("valueToURLValues: %v", err)
+	}
+	if g, w := values.Encode(), "limit=5&term=x"; g != w {
+		t.Errorf("query=%q want %q", g, w)
+	}
+
+	nested := []interface{}{
+		&struct {
+			Term  string `json:"term"`
+			Price price  `json:"price"`
+		}{Term: "x", Price: price{Min: 0.99, Max: 9.99}},
+		&struct {
+			Term  string `json:"term"`
+			Price *price `json:"price"`
+		}{Term: "x", Price: &price{Max: 9.99}},
+		&struct {
+			Prices []price `json:"price"`
+		}{Prices: []price{{Max: 9.99}}},
+	}
+	for i, v := range nested {
+		_, err := valueToURLValues(ctx, v)
+		if err == nil {
+			t.Errorf("#%d: expected an error for a nested struct", i)
+			continue
+		}
+		if !strings.Contains(err.Error(), `"price"`) {
+			t.Errorf("#%d: err=%q should name the offending key", i, err)
+		}
+	}
+}
+
+func TestSearchMany(t *testing.T) {
+	const limit = 2
+	var inFlight, maxInFlight int32
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+
+		term := r.URL.Query().Get("term")
+		if term == "fail" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		if got := r.URL.Query().Get("media"); got != "music" {
+			http.Error(w, "media not carried over from the base search: "+got, http.StatusBadRequest)
+			return
+		}
+		// Finish out of order so that ordering relies on SearchMany.
+		time.Sleep(time.Duration(len(term)%3) * 10 * time.Millisecond)
+		fmt.Fprintf(w, `{"resultCount": 1, "results": [{"trackName": %q}]}`, term)
+	}))
+	defer cst.Close()
+
+	client := &Client{searchURL: cst.URL}
+	base := &Search{Media: "music", Limit: 1}
+	terms := []string{"alpha", "be", "c", "delta", "echoes", "f"}
+	results, err := client.SearchMany(context.Background(), base, terms, limit)
+	if err != nil {
+		t.Fatalf("SearchMany: %v", err)
+	}
+	if g, w := len(results), len(terms); g != w {
+		t.Fatalf("len(results)=%d want %d", g, w)
+	}
+	for i, sres := range results {
+		if g, w := sres.Results[0].TrackName, terms[i]; g != w {
+			t.Errorf("#%d: trackName=%q want %q", i, g, w)
+		}
+	}
+	if g := atomic.LoadInt32(&maxInFlight); g > limit {
+		t.Errorf("max concurrent requests=%d want at most %d", g, limit)
+	}
+	if base.Term != "" {
+		t.Errorf("base search was mutated: Term=%q", base.Term)
+	}
+
+	if _, err := client.SearchMany(context.Background(), base, []string{"a", "fail", "b"}, limit); err == nil {
+		t.Error("expected an error when one of the searches fails")
+	}
+}
+
+func TestLookupEmptyBody(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, " \n\t\n")
+	}))
+	defer cst.Close()
+
+	client := &Client{lookupURL: cst.URL}
+	sres, err := client.SearchById(context.Background(), "0")
+	if err != nil {
+		t.Fatalf("SearchById: %v", err)
+	}
+	if sres.ResultCount != 0 || len(sres.Results) != 0 {
+		t.Errorf("got %+v, want no results", sres)
+	}
+}
+
+func TestSearchMissingTerm(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s", r.URL)
+	}))
+	defer cst.Close()
+
+	client := &Client{searchURL: cst.URL}
+	_, err := client.Search(context.Background(), &Search{Media: "music", Limit: 10})
+	if err != ErrMissingTerm {
+		t.Errorf("err=%v want %v", err, ErrMissingTerm)
+	}
+}
+
+func TestWithRequestObserver(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	var observed []*http.Request
+	client := NewClient(WithRequestObserver(func(req *http.Request) {
+		observed = append(observed, req)
+	}))
+	client.searchURL = cst.URL + "/search"
+
+	if _, err := client.Search(context.Background(), &Search{Term: "ab", Limit: 3}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if g, w := len(observed), 1; g != w {
+		t.Fatalf("observed %d requests want %d", g, w)
+	}
+	req := observed[0]
+	if g, w := req.URL.String(), cst.URL+"/search?limit=3&term=ab"; g != w {
+		t.Errorf("URL=%q want %q", g, w)
+	}
+	if g, w := req.Header.Get("User-Agent"), defaultUserAgent; g != w {
+		t.Errorf("User-Agent=%q want %q", g, w)
+	}
+}
+
+func TestResultKind(t *testing.T) {
+	blob := []byte(`{
+		"resultCount": 5,
+		"results": [
+			{"kind": "song"},
+			{"kind": "feature-movie"},
+			{"kind": "podcast"},
+			{"kind": "software"},
+			{"kind": "music-video"}
+		]
+	}`)
+	sres := new(SearchResult)
+	if err := json.Unmarshal(blob, sres); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := []Kind{KindSong, KindFeatureMovie, KindPodcast, KindSoftware, KindMusicVideo}
+	for i, res := range sres.Results {
+		if g, w := res.Kind, want[i]; g != w {
+			t.Errorf("#%d: Kind=%q want %q", i, g, w)
+		}
+	}
+}
+
+func TestLookupOne(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("id") {
+		case "1":
+			fmt.Fprint(w, `{"resultCount": 1, "results": [{"trackId": 1, "trackName": "One"}]}`)
+		case "2":
+			fmt.Fprint(w, `{"resultCount": 2, "results": [{"trackId": 2}, {"trackId": 3}]}`)
+		default:
+			fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+		}
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	client := &Client{lookupURL: cst.URL}
+
+	if _, err := client.LookupOne(ctx, "0"); err != ErrNotFound {
+		t.Errorf("zero results: err=%v want %v", err, ErrNotFound)
+	}
+
+	res, err := client.LookupOne(ctx, "1")
+	if err != nil {
+		t.Fatalf("one result: %v", err)
+	}
+	if g, w := res.TrackName, "One"; g != w {
+		t.Errorf("one result: TrackName=%q want %q", g, w)
+	}
+
+	res, err = client.LookupOne(ctx, "2")
+	if err == nil || err == ErrNotFound {
+		t.Errorf("many results: err=%v want an error about multiple results", err)
+	}
+	if res != nil {
+		t.Errorf("many results: got %+v want nil", res)
+	}
+}
+
+func TestSearchExtra(t *testing.T) {
+	s := &Search{
+		Term:  "jazz",
+		Limit: 5,
+		Extra: url.Values{
+			"genreId": {"11"},
+			"limit":   {"200"},
+		},
+	}
+	got, err := s.EncodeQuery(context.Background())
+	if err != nil {
+		t.Fatalf("EncodeQuery: %v", err)
+	}
+	if g, w := got, "genreId=11&limit=5&term=jazz"; g != w {
+		t.Errorf("query=%q want %q", g, w)
+	}
+}
+
+func TestSearchMarshalJSONOmitsZeroValues(t *testing.T) {
+	blob, err := json.Marshal(&Search{Term: "x"})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if g, w := string(blob), `{"term":"x"}`; g != w {
+		t.Errorf("json=%s want %s", g, w)
+	}
+}
+
+func TestSearchGenreID(t *testing.T) {
+	s := &Search{Term: "jazz", GenreID: "11"}
+	got, err := s.EncodeQuery(context.Background())
+	if err != nil {
+		t.Fatalf("EncodeQuery: %v", err)
+	}
+	if g, w := got, "genreId=11&term=jazz"; g != w {
+		t.Errorf("query=%q want %q", g, w)
+	}
+}
+
+// TestClientConcurrentUse is most useful when run with -race.
+func TestClientConcurrentUse(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"resultCount": 1, "results": [{"trackName": %q}]}`, r.URL.Query().Get("term"))
+	}))
+	defer cst.Close()
+
+	var observed int32
+	client := NewClient(
+		WithCache(time.Minute),
+		WithNormalizeArtwork(),
+		WithRequestObserver(func(*http.Request) { atomic.AddInt32(&observed, 1) }),
+	)
+	client.searchURL = cst.URL
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			term := fmt.Sprintf("term-%d", i%8)
+			sres, err := client.Search(context.Background(), &Search{Term: term})
+			if err != nil {
+				errs <- err
+				return
+			}
+			if g := sres.Results[0].TrackName; g != term {
+				errs <- fmt.Errorf("trackName=%q want %q", g, term)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if atomic.LoadInt32(&observed) == 0 {
+		t.Error("expected at least one request to reach the backend")
+	}
+}
+
+func TestSearchJSONRoundtrip(t *testing.T) {
+	ctx := context.Background()
+	for _, limit := range []uint{12, 200, 1000000} {
+		blob, err := json.Marshal(&Search{Term: "x", Limit: limit})
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		replayed := new(Search)
+		if err := json.Unmarshal(blob, replayed); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		values, err := valueToURLValues(ctx, replayed)
+		if err != nil {
+			t.Fatalf("valueToURLValues: %v", err)
+		}
+		if g, w := values.Get("limit"), strconv.FormatUint(uint64(limit), 10); g != w {
+			t.Errorf("limit=%q want %q", g, w)
+		}
+	}
+}
+
+func TestSearchJSONRoundtripTerms(t *testing.T) {
+	blob, err := json.Marshal(&Search{Terms: []string{"jack", "johnson"}})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	replayed := new(Search)
+	if err := json.Unmarshal(blob, replayed); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if err := replayed.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	query, err := replayed.EncodeQuery(context.Background())
+	if err != nil {
+		t.Fatalf("EncodeQuery: %v", err)
+	}
+	if g, w := query, "term=jack+johnson"; g != w {
+		t.Errorf("query=%q want %q", g, w)
+	}
+}
+
+func TestSearchJSONRoundtripExtra(t *testing.T) {
+	orig := &Search{Term: "x", Extra: url.Values{"genreId": {"21"}}}
+	blob, err := json.Marshal(orig)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	replayed := new(Search)
+	if err := json.Unmarshal(blob, replayed); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(replayed, orig) {
+		t.Errorf("replayed=%+v\nwant     %+v", replayed, orig)
+	}
+
+	query, err := replayed.EncodeQuery(context.Background())
+	if err != nil {
+		t.Fatalf("EncodeQuery: %v", err)
+	}
+	if g, w := query, "genreId=21&term=x"; g != w {
+		t.Errorf("query=%q want %q", g, w)
+	}
+}
+
+func TestSearchJSONRoundtripEntities(t *testing.T) {
+	orig := &Search{Term: "x", Entity: EntityMusicTrack, Entities: []Entity{EntityMusicVideo}}
+	blob, err := json.Marshal(orig)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	replayed := new(Search)
+	if err := json.Unmarshal(blob, replayed); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(replayed, orig) {
+		t.Errorf("replayed=%+v\nwant     %+v", replayed, orig)
+	}
+
+	query, err := replayed.EncodeQuery(context.Background())
+	if err != nil {
+		t.Fatalf("EncodeQuery: %v", err)
+	}
+	if g, w := query, "entity=musicTrack%2CmusicVideo&term=x"; g != w {
+		t.Errorf("query=%q want %q", g, w)
+	}
+}
+
+func TestResultCollectionArtist(t *testing.T) {
+	blob := []byte(`{
+		"wrapperType": "track",
+		"kind": "song",
+		"artistId": 5468295,
+		"collectionArtistId": 4035426,
+		"artistName": "Daft Punk",
+		"collectionArtistName": "Various Artists",
+		"collectionName": "Now That's What I Call Music!"
+	}`)
+	res := new(Result)
+	if err := json.Unmarshal(blob, res); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if g, w := res.CollectionArtistName, "Various Artists"; g != w {
+		t.Errorf("CollectionArtistName=%q want %q", g, w)
+	}
+	if g, w := res.CollectionArtistId, uint64(4035426); g != w {
+		t.Errorf("CollectionArtistId=%d want %d", g, w)
+	}
+	if g, w := res.ArtistName, "Daft Punk"; g != w {
+		t.Errorf("ArtistName=%q want %q", g, w)
+	}
+}
+
+func TestWithStorefront(t *testing.T) {
+	var storefronts []string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		storefronts = append(storefronts, r.Header.Get("X-Apple-Store-Front"))
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	client := NewClient(WithStorefront("143444-2,32"))
+	client.searchURL = cst.URL
+	client.lookupURL = cst.URL
+	if _, err := client.Search(ctx, &Search{Term: "x"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if _, err := client.SearchById(ctx, "1"); err != nil {
+		t.Fatalf("SearchById: %v", err)
+	}
+	plain := &Client{searchURL: cst.URL}
+	if _, err := plain.Search(ctx, &Search{Term: "x"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	want := []string{"143444-2,32", "143444-2,32", ""}
+	if !reflect.DeepEqual(storefronts, want) {
+		t.Errorf("X-Apple-Store-Front=%q want %q", storefronts, want)
+	}
+}
+
+func TestSearchAll(t *testing.T) {
+	const available = 8
+	var requests int32
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		sres := new(SearchResult)
+		for i := offset; i < offset+limit && i < available; i++ {
+			sres.Results = append(sres.Results, &Result{TrackId: uint64(i + 1)})
+		}
+		sres.ResultCount = uint64(len(sres.Results))
+		json.NewEncoder(w).Encode(sres)
+	}))
+	defer cst.Close()
+
+	client := &Client{searchURL: cst.URL}
+	tests := []struct {
+		max          int
+		wantCount    int
+		wantRequests int32
+	}{
+		{max: 20, wantCount: available, wantRequests: 3},
+		{max: 5, wantCount: 5, wantRequests: 2},
+		{max: 6, wantCount: 6, wantRequests: 2},
+	}
+	for _, tt := range tests {
+		atomic.StoreInt32(&requests, 0)
+		results, err := client.SearchAll(context.Background(), &Search{Term: "x", Limit: 3}, tt.max)
+		if err != nil {
+			t.Fatalf("max=%d: SearchAll: %v", tt.max, err)
+		}
+		if g, w := len(results), tt.wantCount; g != w {
+			t.Errorf("max=%d: got %d results want %d", tt.max, g, w)
+		}
+		for i, res := range results {
+			if g, w := res.TrackId, uint64(i+1); g != w {
+				t.Errorf("max=%d: #%d: TrackId=%d want %d", tt.max, i, g, w)
+			}
+		}
+		if g, w := atomic.LoadInt32(&requests), tt.wantRequests; g != w {
+			t.Errorf("max=%d: made %d requests want %d", tt.max, g, w)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.SearchAll(ctx, &Search{Term: "x", Limit: 3}, 20); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled context: err=%v want %v", err, context.Canceled)
+	}
+}
+
+func TestHasMatches(t *testing.T) {
+	var limits []string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limits = append(limits, r.URL.Query().Get("limit"))
+		if r.URL.Query().Get("term") == "none" {
+			fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+			return
+		}
+		fmt.Fprint(w, `{"resultCount": 1, "results": [{"trackId": 1}]}`)
+	}))
+	defer cst.Close()
+
+	client := &Client{searchURL: cst.URL}
+	s := &Search{Term: "x", Entity: EntityTVEpisode, Limit: 50}
+	ok, err := client.HasMatches(context.Background(), s)
+	if err != nil {
+		t.Fatalf("HasMatches: %v", err)
+	}
+	if !ok {
+		t.Error("HasMatches=false want true")
+	}
+	if g, w := limits, []string{"1"}; !reflect.DeepEqual(g, w) {
+		t.Errorf("limits=%q want %q", g, w)
+	}
+	if g, w := s.Limit, uint(50); g != w {
+		t.Errorf("search was mutated: Limit=%d want %d", g, w)
+	}
+
+	ok, err = client.HasMatches(context.Background(), &Search{Term: "none"})
+	if err != nil {
+		t.Fatalf("HasMatches: %v", err)
+	}
+	if ok {
+		t.Error("HasMatches=true want false")
+	}
+}
+
+// recordingHandler is a slog.Handler that keeps every record it handles.
+type recordingHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *recordingHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	h.records = append(h.records, r)
+	h.mu.Unlock()
+	return nil
+}
+
+func TestWithLogger(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("term") == "fail" {
+			http.Error(w, "boom", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	h := new(recordingHandler)
+	client := NewClient(WithLogger(slog.New(h)))
+	client.searchURL = cst.URL
+
+	ctx := context.Background()
+	if _, err := client.Search(ctx, &Search{Term: "ok"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if _, err := client.Search(ctx, &Search{Term: "fail"}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if g, w := len(h.records), 2; g != w {
+		t.Fatalf("logged %d records want %d", g, w)
+	}
+	wantLevels := []slog.Level{slog.LevelDebug, slog.LevelWarn}
+	wantStatuses := []int64{http.StatusOK, http.StatusServiceUnavailable}
+	for i, rec := range h.records {
+		if g, w := rec.Level, wantLevels[i]; g != w {
+			t.Errorf("#%d: level=%v want %v", i, g, w)
+		}
+		attrs := make(map[string]slog.Value)
+		rec.Attrs(func(a slog.Attr) bool {
+			attrs[a.Key] = a.Value
+			return true
+		})
+		if g, w := attrs["method"].String(), "GET"; g != w {
+			t.Errorf("#%d: method=%q want %q", i, g, w)
+		}
+		if g := attrs["url"].String(); !strings.HasPrefix(g, cst.URL) {
+			t.Errorf("#%d: url=%q want it to start with %q", i, g, cst.URL)
+		}
+		if g, w := attrs["status"].Int64(), wantStatuses[i]; g != w {
+			t.Errorf("#%d: status=%d want %d", i, g, w)
+		}
+		if _, ok := attrs["duration"]; !ok {
+			t.Errorf("#%d: no duration logged", i)
+		}
+	}
+}
+
+func TestWithMaxRedirects(t *testing.T) {
+	redirectTo := func(path string, code int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, path+"?"+r.URL.RawQuery, code)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/search", redirectTo("/us/search", http.StatusFound))
+	mux.Handle("/us/search", redirectTo("/us/v2/search", http.StatusMovedPermanently))
+	mux.HandleFunc("/us/v2/search", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"resultCount": 1, "results": [{"trackName": %q}]}`, r.URL.Query().Get("term"))
+	})
+	cst := httptest.NewServer(mux)
+	defer cst.Close()
+
+	ctx := context.Background()
+	for _, client := range []*Client{new(Client), NewClient(WithMaxRedirects(2))} {
+		client.searchURL = cst.URL + "/search"
+		sres, err := client.Search(ctx, &Search{Term: "moved"})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if g, w := sres.Results[0].TrackName, "moved"; g != w {
+			t.Errorf("TrackName=%q want %q", g, w)
+		}
+	}
+
+	for _, max := range []int{1, 0} {
+		client := NewClient(WithMaxRedirects(max))
+		client.searchURL = cst.URL + "/search"
+		if _, err := client.Search(ctx, &Search{Term: "moved"}); !errors.Is(err, ErrTooManyRedirects) {
+			t.Errorf("max=%d: err=%v want %v", max, err, ErrTooManyRedirects)
+		}
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		s    *Search
+		want string
+	}{
+		{s: &Search{Terms: []string{"hello", "world"}}, want: "term=hello+world"},
+		{s: &Search{Term: "jack", Terms: []string{"johnson", " "}}, want: "term=jack+johnson"},
+		{s: &Search{Terms: []string{"rock & roll"}}, want: "term=rock+%26+roll"},
+	}
+	for _, tt := range tests {
+		if err := tt.s.Validate(); err != nil {
+			t.Errorf("Terms=%q: Validate: %v", tt.s.Terms, err)
+		}
+		got, err := tt.s.EncodeQuery(ctx)
+		if err != nil {
+			t.Fatalf("Terms=%q: EncodeQuery: %v", tt.s.Terms, err)
+		}
+		if g, w := got, tt.want; g != w {
+			t.Errorf("Terms=%q: query=%q want %q", tt.s.Terms, g, w)
+		}
+	}
+
+	if err := (&Search{Terms: []string{""}}).Validate(); err != ErrMissingTerm {
+		t.Errorf("empty Terms: err=%v want %v", err, ErrMissingTerm)
+	}
+}
+
+func TestSearchClone(t *testing.T) {
+	orig := &Search{
+		Term:   "template",
+		Media:  MediaMusic,
+		Limit:  10,
+		Extra:  url.Values{"genreId": {"11", "12"}},
+		Entity: EntityMusicVideo,
+
+		Terms:    []string{"more"},
+		Entities: []Entity{EntityMusicArtist},
+	}
+	clone := orig.Clone()
+	if !reflect.DeepEqual(clone, orig) {
+		t.Fatalf("clone=%+v want %+v", clone, orig)
+	}
+
+	clone.Term = "variant"
+	clone.Limit = 1
+	clone.Extra.Set("genreId", "99")
+	clone.Extra.Add("callback", "cb")
+	clone.Terms[0] = "changed"
+	clone.Entities[0] = EntityTVShow
+
+	if g, w := orig.Term, "template"; g != w {
+		t.Errorf("orig.Term=%q want %q", g, w)
+	}
+	if g, w := orig.Limit, uint(10); g != w {
+		t.Errorf("orig.Limit=%d want %d", g, w)
+	}
+	if g, w := orig.Extra, (url.Values{"genreId": {"11", "12"}}); !reflect.DeepEqual(g, w) {
+		t.Errorf("orig.Extra=%v want %v", g, w)
+	}
+	if g, w := orig.Terms, []string{"more"}; !reflect.DeepEqual(g, w) {
+		t.Errorf("orig.Terms=%q want %q", g, w)
+	}
+	if g, w := orig.Entities, []Entity{EntityMusicArtist}; !reflect.DeepEqual(g, w) {
+		t.Errorf("orig.Entities=%q want %q", g, w)
+	}
+
+	if (*Search)(nil).Clone() != nil {
+		t.Error("Clone of a nil Search should be nil")
+	}
+}
+
+func TestTruncatedResults(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultCount": 3, "results": [{"trackId": 1}]}`)
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	lenient := &Client{searchURL: cst.URL}
+	sres, err := lenient.Search(ctx, &Search{Term: "x"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if !sres.IsTruncated() {
+		t.Error("IsTruncated=false want true")
+	}
+
+	strict := NewClient(WithStrictResultCount())
+	strict.searchURL = cst.URL
+	if _, err := strict.Search(ctx, &Search{Term: "x"}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("strict: err=%v want %v", err, ErrTruncated)
+	}
+
+	whole := &SearchResult{ResultCount: 1, Results: []*Result{{TrackId: 1}}}
+	if whole.IsTruncated() {
+		t.Error("IsTruncated=true for a complete result")
+	}
+}
+
+func TestSearchEntities(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		s    *Search
+		want string
+	}{
+		{
+			s:    &Search{Term: "x", Entities: []Entity{EntityMusicTrack, EntityMusicVideo}},
+			want: "musicTrack,musicVideo",
+		},
+		{
+			s:    &Search{Term: "x", Entity: EntityMusicTrack, Entities: []Entity{EntityMusicVideo}},
+			want: "musicTrack,musicVideo",
+		},
+		{
+			s:    &Search{Term: "x", Entity: EntityMusicVideo},
+			want: "musicVideo",
+		},
+	}
+	for i, tt := range tests {
+		query, err := tt.s.EncodeQuery(ctx)
+		if err != nil {
+			t.Fatalf("#%d: EncodeQuery: %v", i, err)
+		}
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatalf("#%d: ParseQuery: %v", i, err)
+		}
+		if g, w := values["entity"], []string{tt.want}; !reflect.DeepEqual(g, w) {
+			t.Errorf("#%d: entity=%q want %q", i, g, w)
+		}
+	}
+
+	invalid := &Search{Term: "x", Media: MediaMusic, Entities: []Entity{EntityMusicTrack, EntityTVEpisode}}
+	if err := invalid.Validate(); err == nil {
+		t.Error("expected an error for an entity invalid for the media")
+	}
+}
+
+func TestWithMaxResponseBytes(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"resultCount": 1, "results": [{"longDescription": %q}]}`, strings.Repeat("a", 4096))
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	limited := NewClient(WithMaxResponseBytes(1024))
+	limited.searchURL = cst.URL
+	if _, err := limited.Search(ctx, &Search{Term: "big"}); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("err=%v want %v", err, ErrResponseTooLarge)
+	}
+
+	roomy := NewClient(WithMaxResponseBytes(1 << 20))
+	roomy.searchURL = cst.URL
+	if _, err := roomy.Search(ctx, &Search{Term: "big"}); err != nil {
+		t.Errorf("within the limit: %v", err)
+	}
+}
+
+func TestLookupInCountry(t *testing.T) {
+	var gotQuery url.Values
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	client := &Client{lookupURL: cst.URL}
+	if _, err := client.LookupWithParams(context.Background(), "909253", &LookupParams{Country: "GB"}); err != nil {
+		t.Fatalf("LookupWithParams: %v", err)
+	}
+	if g, w := gotQuery.Get("country"), "GB"; g != w {
+		t.Errorf("country=%q want %q", g, w)
+	}
+}
+
+func TestBuildRequest(t *testing.T) {
+	client := NewClient(WithUserAgent("example/1.0"), WithStorefront("143444-2,32"))
+	req, err := client.BuildRequest(context.Background(), &Search{Term: "jack johnson", Limit: 5})
+	if err != nil {
+		t.Fatalf("BuildRequest: %v", err)
+	}
+	if g, w := req.Method, "GET"; g != w {
+		t.Errorf("Method=%q want %q", g, w)
+	}
+	if g, w := req.URL.String(), baseURL+"?limit=5&term=jack+johnson"; g != w {
+		t.Errorf("URL=%q want %q", g, w)
+	}
+	if g, w := req.Header.Get("User-Agent"), "example/1.0"; g != w {
+		t.Errorf("User-Agent=%q want %q", g, w)
+	}
+	if g, w := req.Header.Get("X-Apple-Store-Front"), "143444-2,32"; g != w {
+		t.Errorf("X-Apple-Store-Front=%q want %q", g, w)
+	}
+
+	req, err = client.BuildRequest(context.Background(), &Search{Id: "909253"})
+	if err != nil {
+		t.Fatalf("BuildRequest: %v", err)
+	}
+	if g, w := req.URL.String(), lookupURL+"?id=909253"; g != w {
+		t.Errorf("URL=%q want %q", g, w)
+	}
+
+	if _, err := client.BuildRequest(context.Background(), &Search{}); err != ErrMissingTerm {
+		t.Errorf("err=%v want %v", err, ErrMissingTerm)
+	}
+}
+
+type requestIDKey struct{}
+
+func TestWithRequestIDFromContext(t *testing.T) {
+	var ids []string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get("X-Request-ID"))
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	client := NewClient(WithRequestIDFromContext(requestIDKey{}))
+	client.searchURL = cst.URL
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-42")
+	if _, err := client.Search(ctx, &Search{Term: "x"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	// Contexts without an id send no header.
+	if _, err := client.Search(context.Background(), &Search{Term: "x"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	want := []string{"req-42", ""}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("X-Request-ID=%q want %q", ids, want)
+	}
+}
+
+func TestSearchWithIdForwardsLookupParams(t *testing.T) {
+	var gotQuery url.Values
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	client := &Client{lookupURL: cst.URL}
+	s := &Search{Id: "909253", Entity: EntityMusicVideo, Limit: 5}
+	if _, err := client.Search(context.Background(), s); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	want := url.Values{"id": {"909253"}, "entity": {"musicVideo"}, "limit": {"5"}}
+	if !reflect.DeepEqual(gotQuery, want) {
+		t.Errorf("query=%v want %v", gotQuery, want)
+	}
+}
+
+func TestWithHeader(t *testing.T) {
+	var headers []http.Header
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header)
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	client := NewClient(
+		WithHeader("X-Proxy-Auth", "secret"),
+		WithHeader("X-Tag", "a"),
+		WithHeader("X-Tag", "b"),
+		WithHeader("User-Agent", "ignored"),
+		WithUserAgent("example/1.0"),
+	)
+	client.searchURL = cst.URL
+	client.lookupURL = cst.URL
+
+	ctx := context.Background()
+	if _, err := client.Search(ctx, &Search{Term: "x"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if _, err := client.SearchById(ctx, "1"); err != nil {
+		t.Fatalf("SearchById: %v", err)
+	}
+	rc, err := (&Result{PreviewURL: cst.URL + "/preview.m4a"}).FetchPreview(ctx, client)
+	if err != nil {
+		t.Fatalf("FetchPreview: %v", err)
+	}
+	rc.Close()
+
+	if g, w := len(headers), 3; g != w {
+		t.Fatalf("got %d requests want %d", g, w)
+	}
+	for i, h := range headers {
+		if g, w := h.Get("X-Proxy-Auth"), "secret"; g != w {
+			t.Errorf("#%d: X-Proxy-Auth=%q want %q", i, g, w)
+		}
+		if g, w := h["X-Tag"], []string{"a", "b"}; !reflect.DeepEqual(g, w) {
+			t.Errorf("#%d: X-Tag=%q want %q", i, g, w)
+		}
+		if g, w := h.Get("User-Agent"), "example/1.0"; g != w {
+			t.Errorf("#%d: User-Agent=%q want %q", i, g, w)
+		}
+	}
+}
+
+func TestSearchRawQuery(t *testing.T) {
+	const rawQuery = "term=jack+johnson&entity=musicVideo&genreId=21&limit=5"
+	var gotQuery string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		fmt.Fprint(w, `{"resultCount": 1, "results": [{"trackId": 1}]}`)
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	client := &Client{searchURL: cst.URL}
+	sres, err := client.SearchRawQuery(ctx, rawQuery)
+	if err != nil {
+		t.Fatalf("SearchRawQuery: %v", err)
+	}
+	if g, w := gotQuery, rawQuery; g != w {
+		t.Errorf("query=%q want %q", g, w)
+	}
+	if g, w := len(sres.Results), 1; g != w {
+		t.Errorf("len(Results)=%d want %d", g, w)
+	}
+
+	for _, bad := range []string{"", "term=%zz"} {
+		if _, err := client.SearchRawQuery(ctx, bad); err == nil {
+			t.Errorf("SearchRawQuery(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestSearchValues(t *testing.T) {
+	var gotQuery url.Values
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		fmt.Fprint(w, `{"resultCount": 1, "results": [{"trackId": 1}]}`)
+	}))
+	defer cst.Close()
+
+	v := url.Values{
+		"term":    {"jack johnson"},
+		"genreId": {"21"},
+		"custom":  {"a", "b"},
+	}
+	client := &Client{searchURL: cst.URL}
+	sres, err := client.SearchValues(context.Background(), v)
+	if err != nil {
+		t.Fatalf("SearchValues: %v", err)
+	}
+	if !reflect.DeepEqual(gotQuery, v) {
+		t.Errorf("query=%v want %v", gotQuery, v)
+	}
+	if g, w := len(sres.Results), 1; g != w {
+		t.Errorf("len(Results)=%d want %d", g, w)
+	}
+
+	if _, err := client.SearchValues(context.Background(), nil); err == nil {
+		t.Error("SearchValues(nil): expected an error")
+	}
+}
+
+func TestSearchInto(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultCount": 1, "results": [{
+			"wrapperType": "software",
+			"kind": "software",
+			"trackName": "Pages",
+			"bundleId": "com.apple.Pages",
+			"minimumOsVersion": "16.0",
+			"supportedDevices": ["iPhone15-iPhone15", "iPadPro11M4-iPadPro11M4"]
+		}]}`)
+	}))
+	defer cst.Close()
+
+	type app struct {
+		TrackName        string   `json:"trackName"`
+		BundleID         string   `json:"bundleId"`
+		MinimumOSVersion string   `json:"minimumOsVersion"`
+		SupportedDevices []string `json:"supportedDevices"`
+	}
+	var out struct {
+		ResultCount int   `json:"resultCount"`
+		Results     []app `json:"results"`
+	}
+	client := &Client{searchURL: cst.URL}
+	if err := client.SearchInto(context.Background(), &Search{Term: "pages", Media: MediaSoftware}, &out); err != nil {
+		t.Fatalf("SearchInto: %v", err)
+	}
+
+	want := []app{{
+		TrackName:        "Pages",
+		BundleID:         "com.apple.Pages",
+		MinimumOSVersion: "16.0",
+		SupportedDevices: []string{"iPhone15-iPhone15", "iPadPro11M4-iPadPro11M4"},
+	}}
+	if !reflect.DeepEqual(out.Results, want) {
+		t.Errorf("Results=%+v want %+v", out.Results, want)
+	}
+}
+
+func TestWithBodyReadTimeout(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultCount": 1, "results": [`)
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer cst.Close()
+
+	client := NewClient(WithBodyReadTimeout(50 * time.Millisecond))
+	client.searchURL = cst.URL
+	start := time.Now()
+	_, err := client.Search(context.Background(), &Search{Term: "slow"})
+	if !errors.Is(err, ErrBodyReadTimeout) {
+		t.Errorf("err=%v want %v", err, ErrBodyReadTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Search took %v, the body read wasn't cut short", elapsed)
+	}
+}
+
+func TestWithErrorOnEmpty(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	plain := &Client{searchURL: cst.URL}
+	sres, err := plain.Search(ctx, &Search{Term: "nothing"})
+	if err != nil {
+		t.Fatalf("plain: Search: %v", err)
+	}
+	if g := len(sres.Results); g != 0 {
+		t.Errorf("plain: len(Results)=%d want 0", g)
+	}
+
+	client := NewClient(WithErrorOnEmpty())
+	client.searchURL = cst.URL
+	if _, err := client.Search(ctx, &Search{Term: "nothing"}); err != ErrNoResults {
+		t.Errorf("err=%v want %v", err, ErrNoResults)
+	}
+	if ok, err := client.HasMatches(ctx, &Search{Term: "nothing"}); err != nil || ok {
+		t.Errorf("HasMatches=(%t, %v) want (false, nil)", ok, err)
+	}
+}
+
+func TestArtistDiscography(t *testing.T) {
+	var gotQuery url.Values
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		fmt.Fprint(w, `{"resultCount": 4, "results": [
+			{"wrapperType": "artist", "artistId": 5468295, "artistName": "Daft Punk"},
+			{"wrapperType": "collection", "collectionId": 617154241, "collectionName": "Random Access Memories"},
+			{"wrapperType": "collection", "collectionId": 697194953, "collectionName": "Discovery"},
+			{"wrapperType": "collection", "collectionId": 696881097, "collectionName": "Homework"}
+		]}`)
+	}))
+	defer cst.Close()
+
+	client := &Client{lookupURL: cst.URL}
+	sres, err := client.ArtistDiscography(context.Background(), "5468295", "")
+	if err != nil {
+		t.Fatalf("ArtistDiscography: %v", err)
+	}
+
+	want := url.Values{"id": {"5468295"}, "entity": {"album"}, "limit": {"200"}, "sort": {"recent"}}
+	if !reflect.DeepEqual(gotQuery, want) {
+		t.Errorf("query=%v want %v", gotQuery, want)
+	}
+	var names []string
+	for _, res := range sres.Results {
+		names = append(names, res.CollectionName)
+	}
+	if want := []string{"Random Access Memories", "Discovery", "Homework"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("albums=%q want %q", names, want)
+	}
+	if g, w := sres.ResultCount, uint64(3); g != w {
+		t.Errorf("ResultCount=%d want %d", g, w)
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	cst := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	// The server's certificate is only trusted by its own transport.
+	plain := &Client{searchURL: cst.URL}
+	if _, err := plain.Search(ctx, &Search{Term: "x"}); err == nil {
+		t.Fatal("expected a certificate error without the custom transport")
+	}
+
+	for _, tracing := range []bool{true, false} {
+		client := NewClient(WithTransport(cst.Client().Transport), WithTracing(tracing))
+		client.searchURL = cst.URL
+		if _, err := client.Search(ctx, &Search{Term: "x"}); err != nil {
+			t.Errorf("tracing=%t: Search: %v", tracing, err)
+		}
+	}
+}
+
+func TestWithLookupOnID(t *testing.T) {
+	var paths []string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path+"?"+r.URL.RawQuery)
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	s := &Search{Term: "x", Id: "909253"}
+	for _, lookup := range []bool{true, false} {
+		client := NewClient(WithLookupOnID(lookup))
+		client.searchURL = cst.URL + "/search"
+		client.lookupURL = cst.URL + "/lookup"
+		if _, err := client.Search(ctx, s); err != nil {
+			t.Fatalf("lookup=%t: Search: %v", lookup, err)
+		}
+	}
+
+	want := []string{"/lookup?id=909253", "/search?id=909253&term=x"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("requests=%q want %q", paths, want)
+	}
+}
+
+func TestSearchIdLookupParams(t *testing.T) {
+	var gotQuery string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	client := &Client{lookupURL: cst.URL}
+	s := &Search{Id: "909253", Country: "GB", Entity: EntityAlbum, Limit: 5, Media: MediaMusic}
+	if _, err := client.Search(context.Background(), s); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if g, w := gotQuery, "country=GB&entity=album&id=909253&limit=5"; g != w {
+		t.Errorf("query=%q want %q", g, w)
+	}
+}
+
+// idleTransport counts the calls to its CloseIdleConnections.
+type idleTransport struct {
+	http.RoundTripper
+	closed int32
+}
+
+func (it *idleTransport) CloseIdleConnections() { atomic.AddInt32(&it.closed, 1) }
+
+func TestCloseIdleConnections(t *testing.T) {
+	own := &idleTransport{RoundTripper: http.DefaultTransport}
+	if err := NewClient(WithTransport(own)).Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if g, w := atomic.LoadInt32(&own.closed), int32(1); g != w {
+		t.Errorf("own transport: CloseIdleConnections calls=%d want %d", g, w)
+	}
+
+	// Clients without a transport of their own
+	// leave the shared default transport alone.
+	shared := &idleTransport{RoundTripper: http.DefaultTransport}
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = shared
+	defer func() { http.DefaultTransport = defaultTransport }()
+	if err := new(Client).Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if g := atomic.LoadInt32(&shared.closed); g != 0 {
+		t.Errorf("default transport: CloseIdleConnections calls=%d want 0", g)
+	}
+}
+
+func TestPing(t *testing.T) {
+	var healthy int32 = 1
+	var gotQuery string
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		if atomic.LoadInt32(&healthy) == 0 {
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"resultCount": 1, "results": [{"trackId": 1}]}`)
+	}))
+	defer cst.Close()
+
+	ctx := context.Background()
+	client := &Client{searchURL: cst.URL}
+	if err := client.Ping(ctx); err != nil {
+		t.Errorf("healthy: Ping: %v", err)
+	}
+	if g, w := gotQuery, "term=a&limit=1"; g != w {
+		t.Errorf("query=%q want %q", g, w)
+	}
+
+	atomic.StoreInt32(&healthy, 0)
+	err := client.Ping(ctx)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("unhealthy: err=%v (%T) want an *APIError", err, err)
+	}
+	if g, w := apiErr.StatusCode, http.StatusServiceUnavailable; g != w {
+		t.Errorf("StatusCode=%d want %d", g, w)
+	}
+}
+
+func TestWithTransportTap(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	var tapped []string
+	client := NewClient(WithTransportTap(func(req *http.Request, res *http.Response) {
+		tapped = append(tapped, fmt.Sprintf("%s %d", req.URL, res.StatusCode))
+	}))
+	client.searchURL = cst.URL
+	if _, err := client.Search(context.Background(), &Search{Term: "tap"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	want := []string{cst.URL + "?term=tap 202"}
+	if !reflect.DeepEqual(tapped, want) {
+		t.Errorf("tapped=%q want %q", tapped, want)
+	}
+}
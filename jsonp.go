@@ -0,0 +1,58 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// stripJSONP returns the JSON inside a callback(...); wrapper, or
+// blob as it is if it isn't wrapped in a call to callback.
+func stripJSONP(blob []byte, callback string) []byte {
+	trimmed := bytes.TrimSpace(blob)
+	if !bytes.HasPrefix(trimmed, []byte(callback+"(")) {
+		return blob
+	}
+	trimmed = bytes.TrimSuffix(trimmed, []byte(";"))
+	if !bytes.HasSuffix(trimmed, []byte(")")) {
+		return blob
+	}
+	return trimmed[len(callback)+1 : len(trimmed)-1]
+}
+
+// skipJSONPPrefix consumes the "callback(" that starts a JSONP body
+// in r, if any, leaving the JSON to be read next. The closing
+// parenthesis is left for the caller to ignore.
+func skipJSONPPrefix(r io.Reader, callback string) io.Reader {
+	br := bufio.NewReader(r)
+	prefix := []byte(callback + "(")
+	for {
+		b, err := br.Peek(1)
+		if err != nil || !isSpace(b[0]) {
+			break
+		}
+		br.Discard(1)
+	}
+	if b, err := br.Peek(len(prefix)); err == nil && bytes.Equal(b, prefix) {
+		br.Discard(len(prefix))
+	}
+	return br
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+}
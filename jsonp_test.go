@@ -0,0 +1,83 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithJSONP(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callback := r.URL.Query().Get("callback")
+		if callback == "" {
+			http.Error(w, "missing callback", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, "\n%s({\"resultCount\": 2, \"results\": [{\"trackId\": 1, \"trackName\": \"One\"}, {\"trackId\": 2, \"trackName\": \"Two\"}]});\n", callback)
+	}))
+	defer cst.Close()
+
+	client := NewClient(WithJSONP("handle"))
+	client.searchURL = cst.URL
+	client.lookupURL = cst.URL
+
+	ctx := context.Background()
+	sres, err := client.Search(ctx, &Search{Term: "numbers"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if g, w := sres.ResultCount, uint64(2); g != w {
+		t.Errorf("ResultCount=%d want %d", g, w)
+	}
+	if g, w := sres.Results[1].TrackName, "Two"; g != w {
+		t.Errorf("TrackName=%q want %q", g, w)
+	}
+
+	if _, err := client.SearchById(ctx, "1"); err != nil {
+		t.Errorf("SearchById: %v", err)
+	}
+
+	var names []string
+	err = client.SearchStream(ctx, &Search{Term: "numbers"}, func(res *Result) error {
+		names = append(names, res.TrackName)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SearchStream: %v", err)
+	}
+	if g, w := len(names), 2; g != w {
+		t.Errorf("streamed %d results want %d", g, w)
+	}
+}
+
+func TestStripJSONP(t *testing.T) {
+	tests := [...]struct {
+		in, want string
+	}{
+		{`cb({"resultCount": 0})`, `{"resultCount": 0}`},
+		{" cb({\"resultCount\": 0});\n", `{"resultCount": 0}`},
+		{`{"resultCount": 0}`, `{"resultCount": 0}`},
+		{`other({"resultCount": 0})`, `other({"resultCount": 0})`},
+	}
+	for _, tt := range tests {
+		if g, w := string(stripJSONP([]byte(tt.in), "cb")), tt.want; g != w {
+			t.Errorf("stripJSONP(%q)=%q want %q", tt.in, g, w)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+)
+
+// Measures recorded for every search and lookup.
+var (
+	MeasureLatencyMs = stats.Float64("github.com/orijtech/itunes/latency", "The latency of searches and lookups", stats.UnitMilliseconds)
+	MeasureResults   = stats.Int64("github.com/orijtech/itunes/results", "The number of results returned by a search or lookup", stats.UnitDimensionless)
+	MeasureErrors    = stats.Int64("github.com/orijtech/itunes/errors", "The number of failed searches and lookups", stats.UnitDimensionless)
+
+	MeasureResponseBytes   = stats.Int64("github.com/orijtech/itunes/response_bytes", "The size of the response bodies read from the API", stats.UnitBytes)
+	MeasureDecodeLatencyMs = stats.Float64("github.com/orijtech/itunes/decode_latency", "The time taken to decode responses", stats.UnitMilliseconds)
+)
+
+// Views of the measures, which users can register with view.Register.
+var (
+	LatencyView = &view.View{
+		Name:        "github.com/orijtech/itunes/latency",
+		Description: "The distribution of the latencies of searches and lookups",
+		Measure:     MeasureLatencyMs,
+		Aggregation: view.Distribution(
+			// [0ms, 10ms, 25ms, 50ms, 100ms, 200ms, 400ms, 800ms, 1.5s, 3s, 6s, 12s]
+			0, 10, 25, 50, 100, 200, 400, 800, 1500, 3000, 6000, 12000),
+	}
+
+	ResultsView = &view.View{
+		Name:        "github.com/orijtech/itunes/results",
+		Description: "The distribution of the number of results per search or lookup",
+		Measure:     MeasureResults,
+		Aggregation: view.Distribution(0, 1, 5, 10, 25, 50, 100, 200),
+	}
+
+	ErrorsView = &view.View{
+		Name:        "github.com/orijtech/itunes/errors",
+		Description: "The number of failed searches and lookups",
+		Measure:     MeasureErrors,
+		Aggregation: view.Count(),
+	}
+
+	ResponseBytesView = &view.View{
+		Name:        "github.com/orijtech/itunes/response_bytes",
+		Description: "The distribution of the sizes of response bodies",
+		Measure:     MeasureResponseBytes,
+		Aggregation: view.Distribution(
+			// [0B, 1KiB, 4KiB, 16KiB, 64KiB, 256KiB, 1MiB, 4MiB]
+			0, 1<<10, 4<<10, 16<<10, 64<<10, 256<<10, 1<<20, 4<<20),
+	}
+
+	DecodeLatencyView = &view.View{
+		Name:        "github.com/orijtech/itunes/decode_latency",
+		Description: "The distribution of the times taken to decode responses",
+		Measure:     MeasureDecodeLatencyMs,
+		Aggregation: view.Distribution(0, 0.1, 0.5, 1, 2, 5, 10, 25, 50, 100),
+	}
+
+	DefaultViews = []*view.View{LatencyView, ResultsView, ErrorsView, ResponseBytesView, DecodeLatencyView}
+)
+
+// recordStats records the measures for a search or lookup that
+// started at start and finished with sres and err.
+func recordStats(ctx context.Context, start time.Time, sres *SearchResult, err error) {
+	measurements := []stats.Measurement{MeasureLatencyMs.M(sinceMs(start))}
+	if err != nil {
+		measurements = append(measurements, MeasureErrors.M(1))
+	} else if sres != nil {
+		measurements = append(measurements, MeasureResults.M(int64(len(sres.Results))))
+	}
+	stats.Record(ctx, measurements...)
+}
+
+func sinceMs(start time.Time) float64 {
+	return float64(time.Since(start)) / float64(time.Millisecond)
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats/view"
+)
+
+type viewDataExporter struct {
+	mu   sync.Mutex
+	data []*view.Data
+}
+
+func (vde *viewDataExporter) ExportView(vd *view.Data) {
+	vde.mu.Lock()
+	vde.data = append(vde.data, vd)
+	vde.mu.Unlock()
+}
+
+// find returns the latest exported data of the named view that has rows.
+func (vde *viewDataExporter) find(name string) *view.Data {
+	vde.mu.Lock()
+	defer vde.mu.Unlock()
+	for i := len(vde.data) - 1; i >= 0; i-- {
+		if vd := vde.data[i]; vd.View.Name == name && len(vd.Rows) > 0 {
+			return vd
+		}
+	}
+	return nil
+}
+
+func TestSearchRecordsMetrics(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultCount": 3, "results": [{"trackId": 1}, {"trackId": 2}, {"trackId": 3}]}`)
+	}))
+	defer cst.Close()
+
+	if err := view.Register(DefaultViews...); err != nil {
+		t.Fatalf("view.Register: %v", err)
+	}
+	defer view.Unregister(DefaultViews...)
+
+	exporter := new(viewDataExporter)
+	view.RegisterExporter(exporter)
+	defer view.UnregisterExporter(exporter)
+	view.SetReportingPeriod(10 * time.Millisecond)
+	defer view.SetReportingPeriod(0)
+
+	client := &Client{searchURL: cst.URL}
+	if _, err := client.Search(context.Background(), &Search{Term: "metrics"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	var latency, results *view.Data
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		latency, results = exporter.find(LatencyView.Name), exporter.find(ResultsView.Name)
+		if latency != nil && results != nil {
+			break
+		}
+	}
+	if latency == nil {
+		t.Fatal("no latency measurement was exported")
+	}
+	if g, w := latency.Rows[0].Data.(*view.DistributionData).Count, int64(1); g != w {
+		t.Errorf("latency count=%d want %d", g, w)
+	}
+	if results == nil {
+		t.Fatal("no result count measurement was exported")
+	}
+	if g, w := results.Rows[0].Data.(*view.DistributionData).Mean, 3.0; g != w {
+		t.Errorf("mean result count=%v want %v", g, w)
+	}
+}
+
+func TestSearchRecordsResponseSize(t *testing.T) {
+	const body = `{"resultCount": 1, "results": [{"trackId": 1}]}`
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer cst.Close()
+
+	views := []*view.View{ResponseBytesView, DecodeLatencyView}
+	if err := view.Register(views...); err != nil {
+		t.Fatalf("view.Register: %v", err)
+	}
+	defer view.Unregister(views...)
+
+	exporter := new(viewDataExporter)
+	view.RegisterExporter(exporter)
+	defer view.UnregisterExporter(exporter)
+	view.SetReportingPeriod(10 * time.Millisecond)
+	defer view.SetReportingPeriod(0)
+
+	client := &Client{searchURL: cst.URL}
+	if _, err := client.Search(context.Background(), &Search{Term: "metrics"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	var size, decode *view.Data
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		size, decode = exporter.find(ResponseBytesView.Name), exporter.find(DecodeLatencyView.Name)
+		if size != nil && decode != nil {
+			break
+		}
+	}
+	if size == nil {
+		t.Fatal("no response size measurement was exported")
+	}
+	if g, w := size.Rows[0].Data.(*view.DistributionData).Mean, float64(len(body)); g != w {
+		t.Errorf("mean response size=%v want %v", g, w)
+	}
+	if decode == nil {
+		t.Fatal("no decode latency measurement was exported")
+	}
+	if g, w := decode.Rows[0].Data.(*view.DistributionData).Count, int64(1); g != w {
+		t.Errorf("decode count=%d want %d", g, w)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import "strconv"
+
+// Money pairs a price with the ISO 4217 code of its currency.
+type Money struct {
+	Amount   float64
+	Currency string
+}
+
+// zeroDecimalCurrencies are the currencies that have no minor unit.
+var zeroDecimalCurrencies = map[string]bool{
+	"CLP": true,
+	"ISK": true,
+	"JPY": true,
+	"KRW": true,
+	"PYG": true,
+	"UGX": true,
+	"VND": true,
+}
+
+// String formats m with as many decimal places as its
+// currency uses, for example "USD 1.29" or "JPY 250".
+func (m Money) String() string {
+	prec := 2
+	if zeroDecimalCurrencies[m.Currency] {
+		prec = 0
+	}
+	amount := strconv.FormatFloat(m.Amount, 'f', prec, 64)
+	if m.Currency == "" {
+		return amount
+	}
+	return m.Currency + " " + amount
+}
+
+// TrackPriceMoney returns the track's price in the result's currency.
+func (r *Result) TrackPriceMoney() Money {
+	return Money{Amount: r.TrackPrice, Currency: r.Currency}
+}
+
+// CollectionPriceMoney returns the collection's price in the result's currency.
+func (r *Result) CollectionPriceMoney() Money {
+	return Money{Amount: r.CollectionPrice, Currency: r.Currency}
+}
@@ -0,0 +1,59 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestResultPriceMoney(t *testing.T) {
+	tests := []struct {
+		blob           string
+		wantTrack      Money
+		wantTrackStr   string
+		wantCollection string
+	}{
+		{
+			blob:           `{"trackPrice": 1.29, "collectionPrice": 9.99, "currency": "USD"}`,
+			wantTrack:      Money{Amount: 1.29, Currency: "USD"},
+			wantTrackStr:   "USD 1.29",
+			wantCollection: "USD 9.99",
+		},
+		{
+			blob:           `{"trackPrice": 250, "collectionPrice": 2037, "currency": "JPY"}`,
+			wantTrack:      Money{Amount: 250, Currency: "JPY"},
+			wantTrackStr:   "JPY 250",
+			wantCollection: "JPY 2037",
+		},
+	}
+
+	for i, tt := range tests {
+		res := new(Result)
+		if err := json.Unmarshal([]byte(tt.blob), res); err != nil {
+			t.Errorf("#%d: Unmarshal: %v", i, err)
+			continue
+		}
+		if g, w := res.TrackPriceMoney(), tt.wantTrack; g != w {
+			t.Errorf("#%d: TrackPriceMoney=%+v want %+v", i, g, w)
+		}
+		if g, w := res.TrackPriceMoney().String(), tt.wantTrackStr; g != w {
+			t.Errorf("#%d: track price=%q want %q", i, g, w)
+		}
+		if g, w := res.CollectionPriceMoney().String(), tt.wantCollection; g != w {
+			t.Errorf("#%d: collection price=%q want %q", i, g, w)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// NewClient returns a Client configured with opts.
+func NewClient(opts ...Option) *Client {
+	c := new(Client)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithDefaultTimeout bounds every request to d, but only when
+// the caller's context doesn't already carry a deadline.
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.defaultTimeout = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every
+// request, in place of the default "orijtech-itunes/<version>".
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithHeader adds the header key: value to every request that the
+// client sends, including lookups and preview and artwork downloads.
+// It can be repeated, also for the same key to send several values.
+// The headers that the client sets itself, such as User-Agent, take
+// precedence; use their dedicated options to change them instead.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		if c.header == nil {
+			c.header = make(http.Header)
+		}
+		c.header.Add(key, value)
+	}
+}
+
+// WithStorefront sends storefrontID in the X-Apple-Store-Front header
+// of every request, which selects the regional store more precisely
+// than the country parameter.
+func WithStorefront(storefrontID string) Option {
+	return func(c *Client) {
+		c.storefront = storefrontID
+	}
+}
+
+// WithLogger logs every request to logger: its method, URL, status
+// and duration at debug level, or at warn level if it failed.
+// By default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithTransport sends requests through base instead of
+// http.DefaultTransport, e.g. an *http.Transport with a custom
+// TLSClientConfig or proxy. Unless tracing is disabled, base is
+// wrapped to trace the requests.
+func WithTransport(base http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = base
+	}
+}
+
+// WithMaxRedirects caps the number of redirects that a request
+// follows at n, after which it fails with ErrTooManyRedirects. An n
+// of zero stops redirects from being followed. The default is 10.
+func WithMaxRedirects(n int) Option {
+	return func(c *Client) {
+		if n <= 0 {
+			n = -1
+		}
+		c.maxRedirects = n
+	}
+}
+
+// WithTracing turns OpenCensus tracing of requests on or off. With
+// tracing off, no spans are started and requests go through a plain
+// http.Transport instead of the ochttp one. Tracing is on by default.
+func WithTracing(enabled bool) Option {
+	return func(c *Client) {
+		c.disableTracing = !enabled
+	}
+}
+
+// WithLookupOnID sets whether a Search with an Id is sent to the lookup
+// endpoint, as by default, or, if lookup is false, to the search
+// endpoint like any other Search, with the Id as its id parameter.
+func WithLookupOnID(lookup bool) Option {
+	return func(c *Client) {
+		c.disableLookupOnID = !lookup
+	}
+}
+
+// WithStrictResultCount makes searches and lookups fail with
+// ErrTruncated when a response holds fewer results than its
+// resultCount, see SearchResult.IsTruncated.
+func WithStrictResultCount() Option {
+	return func(c *Client) {
+		c.strictResultCount = true
+	}
+}
+
+// WithErrorOnEmpty makes searches that find nothing fail with
+// ErrNoResults instead of returning an empty SearchResult. HasMatches
+// and SearchAll still report no results as false and none.
+func WithErrorOnEmpty() Option {
+	return func(c *Client) {
+		c.errorOnEmpty = true
+	}
+}
+
+// WithMaxResponseBytes fails searches and lookups whose response
+// body is larger than n bytes with ErrResponseTooLarge, instead of
+// buffering it all. By default response bodies aren't limited.
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseBytes = n
+	}
+}
+
+// WithTransportTap calls tap after every round trip that the client
+// makes, including each redirect, with the request and its response,
+// which is nil if the round trip failed. It is meant for debugging
+// and tests; tap must not read or close the response body.
+func WithTransportTap(tap func(*http.Request, *http.Response)) Option {
+	return func(c *Client) {
+		c.tap = tap
+	}
+}
+
+// WithRequestIDFromContext sends the value stored under key in the
+// context of each search or lookup, if any, as the X-Request-ID header.
+func WithRequestIDFromContext(key interface{}) Option {
+	return func(c *Client) {
+		c.requestIDKey = key
+	}
+}
+
+// WithRateLimit spaces out the requests that the client sends to at
+// most rps per second on average, allowing bursts of up to burst
+// requests, e.g. WithRateLimit(20.0/60, 1) for Apple's guidance of
+// about 20 requests per minute. Requests wait for their turn unless
+// their context is done first. Cached responses aren't limited.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		if burst < 1 {
+			burst = 1
+		}
+		c.limiter = rate.NewLimiter(rate.Limit(rps), burst)
+	}
+}
+
+// WithBodyReadTimeout fails searches and lookups with ErrBodyReadTimeout
+// if reading their response body, once its headers have arrived, takes
+// longer than d, e.g. because a server trickles it. It applies on top
+// of any deadline of the request's context.
+func WithBodyReadTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.bodyReadTimeout = d
+	}
+}
+
+// WithCache caches successful search and lookup responses in memory,
+// keyed by their request URL, and serves repeated requests from the
+// cache for ttl instead of going over the network. The cache sweeps
+// expired responses in the background until the client is closed.
+func WithCache(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.cache = newResponseCache(ttl)
+	}
+}
+
+// WithConditionalCache keeps the bodies of responses that carry an
+// ETag or Last-Modified header and revalidates them on later requests
+// for the same URL with If-None-Match or If-Modified-Since, reusing the
+// kept body when the server replies 304 Not Modified. Unlike WithCache,
+// every request still reaches the server. Only the bodies of the 256
+// most recently used URLs are kept.
+func WithConditionalCache() Option {
+	return func(c *Client) {
+		c.validators = newValidatorCache(defaultMaxValidatedEntries)
+	}
+}
+
+// WithSingleflight collapses concurrent identical searches and
+// lookups into a single request, whose response all of the callers
+// share. The request is bound to the context of the caller that
+// sent it, so that caller's cancellation fails the others too.
+func WithSingleflight() Option {
+	return func(c *Client) {
+		c.flights = newFlightGroup()
+	}
+}
+
+// WithRequestObserver calls observe with every request just before it
+// is sent, e.g. to audit the URLs and headers the client uses. observe
+// must not modify the request.
+func WithRequestObserver(observe func(*http.Request)) Option {
+	return func(c *Client) {
+		c.observeRequest = observe
+	}
+}
+
+// WithNormalizeArtwork fills in the 30px and 60px artwork
+// URLs of results that only came with the 100px one.
+func WithNormalizeArtwork() Option {
+	return func(c *Client) {
+		c.normalizeArtwork = true
+	}
+}
+
+// WithNormalizeTokens corrects the casing of the known media and
+// entities of searches before sending them, e.g. "Music" to "music",
+// as the API silently finds nothing for tokens cased otherwise.
+func WithNormalizeTokens() Option {
+	return func(c *Client) {
+		c.normalizeTokens = true
+	}
+}
+
+// WithNormalizeURLs rewrites the URLs of results, such as their
+// previewUrl and artwork URLs, into absolute https URLs when the
+// API sends them protocol-relative, over http or relative.
+func WithNormalizeURLs() Option {
+	return func(c *Client) {
+		c.normalizeURLs = true
+	}
+}
+
+// WithJSONP requests the JSONP variant of the API, for networks that
+// only allow it through, by sending callback as the callback parameter.
+// The callback(...) wrapper is stripped before responses are decoded.
+func WithJSONP(callback string) Option {
+	return func(c *Client) {
+		c.jsonpCallback = callback
+	}
+}
+
+// withDefaultTimeout derives a context bounded by the
+// client's default timeout if ctx has no deadline of its own.
+func (c *Client) withDefaultTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.defaultTimeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.defaultTimeout)
+}
+
+// CallOption configures a single call to Search or SearchById,
+// overriding the client's own options for that call only. Unlike
+// an Option, it can't set up state that outlives the call.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	timeout  time.Duration
+	priority bool
+	header   http.Header
+}
+
+// WithCallTimeout bounds the call to d in place of the client's
+// default timeout, see WithDefaultTimeout. Like the default timeout,
+// it only applies when the caller's context doesn't carry a deadline.
+func WithCallTimeout(d time.Duration) CallOption {
+	return func(co *callOptions) {
+		co.timeout = d
+	}
+}
+
+// WithPriority exempts the call from the rate limit
+// set up by WithRateLimit, e.g.
+//
+//	sres, err := client.Search(ctx, s, itunes.WithPriority())
+//
+// for the few searches that shouldn't wait behind the others.
+func WithPriority() CallOption {
+	return func(co *callOptions) {
+		co.priority = true
+	}
+}
+
+// WithCallHeader adds the header key: value to the requests of the
+// call, on top of those added WithHeader. It can be repeated.
+func WithCallHeader(key, value string) CallOption {
+	return func(co *callOptions) {
+		if co.header == nil {
+			co.header = make(http.Header)
+		}
+		co.header.Add(key, value)
+	}
+}
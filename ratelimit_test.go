@@ -0,0 +1,107 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	var arrivals []time.Time
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrivals = append(arrivals, time.Now())
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	const rps, burst = 20, 2
+	client := NewClient(WithRateLimit(rps, burst))
+	client.searchURL = cst.URL
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if _, err := client.Search(ctx, &Search{Term: "x"}); err != nil {
+			t.Fatalf("Search #%d: %v", i, err)
+		}
+	}
+
+	// The burst goes out at once and the rest are spaced 1/rps apart,
+	// give or take some slack for the timers.
+	const interval = time.Second / rps
+	const slack = 10 * time.Millisecond
+	for i := burst; i < len(arrivals); i++ {
+		if gap := arrivals[i].Sub(arrivals[i-1]); gap < interval-slack {
+			t.Errorf("request #%d came %v after the previous one, want at least %v", i, gap, interval)
+		}
+	}
+	if g, w := arrivals[len(arrivals)-1].Sub(arrivals[0]), 3*interval-slack; g < w {
+		t.Errorf("5 requests took %v want at least %v", g, w)
+	}
+}
+
+func TestWithPriority(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	// With the burst spent, the next search would wait for a second.
+	client := NewClient(WithRateLimit(1, 1))
+	client.searchURL = cst.URL
+	ctx := context.Background()
+	if _, err := client.Search(ctx, &Search{Term: "x"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.Search(ctx, &Search{Term: "x"}, WithPriority()); err != nil {
+		t.Fatalf("Search with priority: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 500*time.Millisecond {
+		t.Errorf("priority search took %v, want it to skip the rate limit", elapsed)
+	}
+	if client.limiter == nil {
+		t.Error("WithPriority removed the client's own rate limit")
+	}
+}
+
+func TestWithRateLimitHonorsContext(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	client := NewClient(WithRateLimit(0.1, 1))
+	client.searchURL = cst.URL
+	if _, err := client.Search(context.Background(), &Search{Term: "x"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	// The next token is 10s away, past the context's deadline,
+	// so the search fails without waiting for it.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.Search(ctx, &Search{Term: "x"}); err == nil {
+		t.Error("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("search took %v, want it to fail before the deadline", elapsed)
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UniqueByTrackID returns a new SearchResult without the results
+// whose trackId already appeared earlier, preserving their order.
+// Results without a trackId, such as artists, are always kept.
+func (sr *SearchResult) UniqueByTrackID() *SearchResult {
+	seen := make(map[uint64]bool)
+	unique := new(SearchResult)
+	for _, res := range sr.Results {
+		if res.TrackId != 0 {
+			if seen[res.TrackId] {
+				continue
+			}
+			seen[res.TrackId] = true
+		}
+		unique.Results = append(unique.Results, res)
+	}
+	unique.ResultCount = uint64(len(unique.Results))
+	return unique
+}
+
+// UniqueByCollectionID returns a new SearchResult with only the first
+// result of each collectionId, preserving their order, which suits
+// album-level views. Results without a collectionId are always kept.
+func (sr *SearchResult) UniqueByCollectionID() *SearchResult {
+	seen := make(map[uint64]bool)
+	unique := new(SearchResult)
+	for _, res := range sr.Results {
+		if res.CollectionId != 0 {
+			if seen[res.CollectionId] {
+				continue
+			}
+			seen[res.CollectionId] = true
+		}
+		unique.Results = append(unique.Results, res)
+	}
+	unique.ResultCount = uint64(len(unique.Results))
+	return unique
+}
+
+// ViewURL returns the most specific iTunes page for the result:
+// its trackViewUrl, else its collectionViewUrl, else its artistViewUrl.
+func (r *Result) ViewURL() string {
+	switch {
+	case r.TrackViewURL != "":
+		return r.TrackViewURL
+	case r.CollectionViewURL != "":
+		return r.CollectionViewURL
+	default:
+		return r.ArtistViewURL
+	}
+}
+
+// IsExplicit reports whether the result is marked as explicit, going
+// by its trackExplicitness or else, e.g. for albums, its collectionExplicitness.
+func (r *Result) IsExplicit() bool {
+	if r.TrackExplicitness != "" {
+		return r.TrackExplicitness == Explicit
+	}
+	return r.CollectionExplicitness == Explicit
+}
+
+// Top returns a new SearchResult with at most the first n results.
+// A non-positive n yields no results.
+func (sr *SearchResult) Top(n int) *SearchResult {
+	if n < 0 {
+		n = 0
+	}
+	if n > len(sr.Results) {
+		n = len(sr.Results)
+	}
+	top := &SearchResult{Results: make([]*Result, n)}
+	copy(top.Results, sr.Results)
+	top.ResultCount = uint64(n)
+	return top
+}
+
+// PreviewKind tells whether a result's preview is audio or video.
+type PreviewKind int
+
+const (
+	PreviewUnknown PreviewKind = iota
+	PreviewAudio
+	PreviewVideo
+)
+
+func (pk PreviewKind) String() string {
+	switch pk {
+	case PreviewAudio:
+		return "audio"
+	case PreviewVideo:
+		return "video"
+	default:
+		return "unknown"
+	}
+}
+
+// PreviewKind infers whether the result's previewUrl is audio or
+// video, from the URL's file extension or else from the result's kind.
+func (r *Result) PreviewKind() PreviewKind {
+	if r.PreviewURL == "" {
+		return PreviewUnknown
+	}
+
+	ext := r.PreviewURL
+	if u, err := url.Parse(r.PreviewURL); err == nil {
+		ext = u.Path
+	}
+	switch strings.ToLower(path.Ext(ext)) {
+	case ".m4a", ".mp3", ".aac", ".wav":
+		return PreviewAudio
+	case ".m4v", ".mp4", ".mov":
+		return PreviewVideo
+	}
+
+	switch r.Kind {
+	case KindSong, KindPodcast, KindPodcastEpisode, KindBook, KindCoachedAudio:
+		return PreviewAudio
+	case KindMusicVideo, KindFeatureMovie, KindTVEpisode:
+		return PreviewVideo
+	}
+	return PreviewUnknown
+}
+
+// ArtistSearch returns a Search for other works by the result's
+// artist, matching its artistName against artist names only.
+func (r *Result) ArtistSearch() *Search {
+	return &Search{Term: r.ArtistName, Attribute: AttributeArtistTerm}
+}
+
+// IsTruncated reports whether the API claimed more
+// results in resultCount than it actually sent.
+func (sr *SearchResult) IsTruncated() bool {
+	return sr.ResultCount > uint64(len(sr.Results))
+}
+
+// FilterByCountry returns a new SearchResult with only the results
+// whose country matches code, ignoring case. Note that results carry
+// three-letter country codes such as "USA" or "GBR".
+func (sr *SearchResult) FilterByCountry(code string) *SearchResult {
+	filtered := new(SearchResult)
+	for _, res := range sr.Results {
+		if strings.EqualFold(res.Country, code) {
+			filtered.Results = append(filtered.Results, res)
+		}
+	}
+	filtered.ResultCount = uint64(len(filtered.Results))
+	return filtered
+}
+
+// SortField is a Result field that SearchResult.SortBy can sort on.
+type SortField int
+
+const (
+	SortByTrackName SortField = iota
+	SortByTrackPrice
+	SortByReleaseDate
+	SortByTrackNumber
+)
+
+// SortBy sorts the results in place by field, in ascending order
+// if asc is set or else in descending order. Results that compare
+// equal keep their relative order.
+func (sr *SearchResult) SortBy(field SortField, asc bool) {
+	var less func(a, b *Result) bool
+	switch field {
+	case SortByTrackName:
+		less = func(a, b *Result) bool { return a.TrackName < b.TrackName }
+	case SortByTrackPrice:
+		less = func(a, b *Result) bool { return a.TrackPrice < b.TrackPrice }
+	case SortByReleaseDate:
+		less = func(a, b *Result) bool { return a.ReleaseDate.Before(b.ReleaseDate) }
+	case SortByTrackNumber:
+		less = func(a, b *Result) bool { return a.TrackNumber < b.TrackNumber }
+	default:
+		return
+	}
+
+	sort.SliceStable(sr.Results, func(i, j int) bool {
+		if asc {
+			return less(sr.Results[i], sr.Results[j])
+		}
+		return less(sr.Results[j], sr.Results[i])
+	})
+}
+
+// GroupByKind buckets the results by their kind, keeping their order
+// within each bucket. Results without a kind, such as artists and
+// collections, are bucketed by their wrapperType instead.
+func (sr *SearchResult) GroupByKind() map[string][]*Result {
+	groups := make(map[string][]*Result)
+	for _, res := range sr.Results {
+		key := string(res.Kind)
+		if key == "" {
+			key = string(res.WrapperType)
+		}
+		groups[key] = append(groups[key], res)
+	}
+	return groups
+}
+
+// Genre is one of the genres that a result is listed under.
+type Genre struct {
+	ID, Name string
+}
+
+// GenrePairs pairs up the result's genreIds with its genres by position.
+// If one list is longer than the other, its extra entries are returned
+// with an empty Name or ID respectively.
+func (r *Result) GenrePairs() []Genre {
+	n := len(r.GenreIDs)
+	if len(r.Genres) > n {
+		n = len(r.Genres)
+	}
+	if n == 0 {
+		return nil
+	}
+	pairs := make([]Genre, n)
+	for i := range pairs {
+		if i < len(r.GenreIDs) {
+			pairs[i].ID = r.GenreIDs[i]
+		}
+		if i < len(r.Genres) {
+			pairs[i].Name = r.Genres[i]
+		}
+	}
+	return pairs
+}
+
+// CollectionShareURL returns the public web page of the result's
+// collection: its collectionViewUrl or else one built from its
+// collectionId, e.g. "https://music.apple.com/us/album/1440857781".
+// The storefront is only included if the result's country is a
+// two-letter code, as otherwise Apple picks the viewer's. It
+// returns "" if the result has neither.
+func (r *Result) CollectionShareURL() string {
+	if r.CollectionViewURL != "" {
+		return r.CollectionViewURL
+	}
+	if r.CollectionId == 0 {
+		return ""
+	}
+	shareURL := "https://music.apple.com/"
+	if len(r.Country) == 2 {
+		shareURL += strings.ToLower(r.Country) + "/"
+	}
+	return shareURL + "album/" + strconv.FormatUint(r.CollectionId, 10)
+}
+
+// AdvisoryRating returns the result's content advisory rating, e.g.
+// "PG-13" for movies, "TV-MA" for TV shows or "Explicit" for songs,
+// or "" if it isn't rated.
+func (r *Result) AdvisoryRating() string {
+	return strings.TrimSpace(r.ContentAdvisoryRating)
+}
+
+// DisplayCollectionName returns the result's collectionCensoredName
+// if censored is set and it has one, or else its collectionName.
+func (r *Result) DisplayCollectionName(censored bool) string {
+	if censored && r.CollectionCensoredName != "" {
+		return r.CollectionCensoredName
+	}
+	return r.CollectionName
+}
+
+// TotalDuration sums the trackTimeMillis of all the results, such as
+// for the running time of a playlist. Results without one are ignored.
+func (sr *SearchResult) TotalDuration() time.Duration {
+	var total time.Duration
+	for _, res := range sr.Results {
+		total += time.Duration(res.TrackTimeMillis) * time.Millisecond
+	}
+	return total
+}
+
+// FilterByPrice returns a new SearchResult with only the results whose
+// trackPrice is between min and max inclusive. Free and price-less
+// results have a trackPrice of 0 and so are only kept if min is 0.
+func (sr *SearchResult) FilterByPrice(min, max float64) *SearchResult {
+	filtered := new(SearchResult)
+	for _, res := range sr.Results {
+		if res.TrackPrice >= min && res.TrackPrice <= max {
+			filtered.Results = append(filtered.Results, res)
+		}
+	}
+	filtered.ResultCount = uint64(len(filtered.Results))
+	return filtered
+}
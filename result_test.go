@@ -0,0 +1,478 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUniqueByTrackID(t *testing.T) {
+	blob := []byte(`{
+		"resultCount": 4,
+		"results": [
+			{"wrapperType": "track", "trackId": 1, "trackName": "Song"},
+			{"wrapperType": "artist", "artistId": 9, "artistName": "Artist"},
+			{"wrapperType": "track", "trackId": 1, "trackName": "Song", "collectionName": "Greatest Hits"},
+			{"wrapperType": "track", "trackId": 2, "trackName": "Other Song"}
+		]
+	}`)
+	sres := new(SearchResult)
+	if err := json.Unmarshal(blob, sres); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	unique := sres.UniqueByTrackID()
+	if g, w := unique.ResultCount, uint64(3); g != w {
+		t.Errorf("ResultCount=%d want %d", g, w)
+	}
+	want := []*Result{sres.Results[0], sres.Results[1], sres.Results[3]}
+	if g, w := len(unique.Results), len(want); g != w {
+		t.Fatalf("len(Results)=%d want %d", g, w)
+	}
+	for i, res := range unique.Results {
+		if res != want[i] {
+			t.Errorf("#%d: got %+v want %+v", i, res, want[i])
+		}
+	}
+	if g, w := len(sres.Results), 4; g != w {
+		t.Errorf("original was modified: len(Results)=%d want %d", g, w)
+	}
+}
+
+func TestUniqueByCollectionID(t *testing.T) {
+	blob := []byte(`{
+		"resultCount": 5,
+		"results": [
+			{"wrapperType": "track", "trackId": 1, "collectionId": 10, "trackName": "One"},
+			{"wrapperType": "track", "trackId": 2, "collectionId": 10, "trackName": "Two"},
+			{"wrapperType": "artist", "artistId": 9, "artistName": "Artist"},
+			{"wrapperType": "track", "trackId": 3, "collectionId": 20, "trackName": "Three"},
+			{"wrapperType": "track", "trackId": 4, "collectionId": 10, "trackName": "Four"}
+		]
+	}`)
+	sres := new(SearchResult)
+	if err := json.Unmarshal(blob, sres); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	unique := sres.UniqueByCollectionID()
+	if g, w := unique.ResultCount, uint64(3); g != w {
+		t.Errorf("ResultCount=%d want %d", g, w)
+	}
+	want := []*Result{sres.Results[0], sres.Results[2], sres.Results[3]}
+	if g, w := len(unique.Results), len(want); g != w {
+		t.Fatalf("len(Results)=%d want %d", g, w)
+	}
+	for i, res := range unique.Results {
+		if res != want[i] {
+			t.Errorf("#%d: got %+v want %+v", i, res, want[i])
+		}
+	}
+	if g, w := len(sres.Results), 5; g != w {
+		t.Errorf("original was modified: len(Results)=%d want %d", g, w)
+	}
+}
+
+func TestResultViewURL(t *testing.T) {
+	const (
+		track      = "https://itunes.apple.com/us/album/upside-down/1?i=2"
+		collection = "https://itunes.apple.com/us/album/curious-george/1"
+		artist     = "https://itunes.apple.com/us/artist/jack-johnson/3"
+	)
+	tests := []struct {
+		res  *Result
+		want string
+	}{
+		{res: &Result{TrackViewURL: track, CollectionViewURL: collection, ArtistViewURL: artist}, want: track},
+		{res: &Result{CollectionViewURL: collection, ArtistViewURL: artist}, want: collection},
+		{res: &Result{TrackViewURL: track, ArtistViewURL: artist}, want: track},
+		{res: &Result{ArtistViewURL: artist}, want: artist},
+		{res: &Result{}, want: ""},
+	}
+	for i, tt := range tests {
+		if g, w := tt.res.ViewURL(), tt.want; g != w {
+			t.Errorf("#%d: ViewURL=%q want %q", i, g, w)
+		}
+	}
+}
+
+func TestResultIsExplicit(t *testing.T) {
+	blob := []byte(`{
+		"resultCount": 3,
+		"results": [
+			{"trackName": "Explicit Song", "trackExplicitness": "explicit", "collectionExplicitness": "explicit"},
+			{"trackName": "Clean Song", "trackExplicitness": "cleaned", "collectionExplicitness": "explicit"},
+			{"collectionName": "Album", "collectionExplicitness": "notExplicit"}
+		]
+	}`)
+	sres := new(SearchResult)
+	if err := json.Unmarshal(blob, sres); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	explicit, clean, album := sres.Results[0], sres.Results[1], sres.Results[2]
+	if g, w := explicit.TrackExplicitness, Explicit; g != w {
+		t.Errorf("TrackExplicitness=%q want %q", g, w)
+	}
+	if g, w := clean.TrackExplicitness, Cleaned; g != w {
+		t.Errorf("TrackExplicitness=%q want %q", g, w)
+	}
+	if g, w := album.CollectionExplicitness, NotExplicit; g != w {
+		t.Errorf("CollectionExplicitness=%q want %q", g, w)
+	}
+	if !explicit.IsExplicit() {
+		t.Errorf("%q: IsExplicit=false want true", explicit.TrackName)
+	}
+	if clean.IsExplicit() {
+		t.Errorf("%q: IsExplicit=true want false", clean.TrackName)
+	}
+	if album.IsExplicit() {
+		t.Errorf("%q: IsExplicit=true want false", album.CollectionName)
+	}
+}
+
+func TestSearchResultTop(t *testing.T) {
+	sres := &SearchResult{
+		ResultCount: 3,
+		Results:     []*Result{{TrackId: 1}, {TrackId: 2}, {TrackId: 3}},
+	}
+	tests := []struct {
+		n    int
+		want []uint64
+	}{
+		{n: 2, want: []uint64{1, 2}},
+		{n: 3, want: []uint64{1, 2, 3}},
+		{n: 10, want: []uint64{1, 2, 3}},
+		{n: 0, want: nil},
+		{n: -1, want: nil},
+	}
+	for _, tt := range tests {
+		top := sres.Top(tt.n)
+		if g, w := top.ResultCount, uint64(len(tt.want)); g != w {
+			t.Errorf("Top(%d): ResultCount=%d want %d", tt.n, g, w)
+		}
+		var got []uint64
+		for _, res := range top.Results {
+			got = append(got, res.TrackId)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Top(%d): trackIds=%v want %v", tt.n, got, tt.want)
+		}
+	}
+	if g, w := len(sres.Results), 3; g != w {
+		t.Errorf("original was modified: len(Results)=%d want %d", g, w)
+	}
+}
+
+func TestResultPreviewKind(t *testing.T) {
+	tests := []struct {
+		res  *Result
+		want PreviewKind
+	}{
+		{
+			res:  &Result{Kind: KindSong, PreviewURL: "https://audio-ssl.itunes.apple.com/a/b/mzaf_1.plus.aac.p.m4a"},
+			want: PreviewAudio,
+		},
+		{
+			res:  &Result{Kind: KindMusicVideo, PreviewURL: "https://video-ssl.itunes.apple.com/a/b/mzvf_2.640x480.h264lc.u.p.m4v"},
+			want: PreviewVideo,
+		},
+		{
+			res:  &Result{Kind: KindMusicVideo, PreviewURL: "https://example.com/preview?id=2"},
+			want: PreviewVideo,
+		},
+		{
+			res:  &Result{Kind: KindSong, PreviewURL: "https://example.com/preview"},
+			want: PreviewAudio,
+		},
+		{res: &Result{Kind: KindSong}, want: PreviewUnknown},
+		{res: &Result{Kind: KindSoftware, PreviewURL: "https://example.com/preview"}, want: PreviewUnknown},
+	}
+	for i, tt := range tests {
+		if g, w := tt.res.PreviewKind(), tt.want; g != w {
+			t.Errorf("#%d: PreviewKind=%v want %v", i, g, w)
+		}
+	}
+}
+
+func TestFilterByCountry(t *testing.T) {
+	sres := &SearchResult{
+		ResultCount: 4,
+		Results: []*Result{
+			{TrackId: 1, Country: "USA"},
+			{TrackId: 2, Country: "GBR"},
+			{TrackId: 3, Country: "USA"},
+			{TrackId: 4, Country: "JPN"},
+		},
+	}
+	tests := []struct {
+		code string
+		want []uint64
+	}{
+		{code: "USA", want: []uint64{1, 3}},
+		{code: "gbr", want: []uint64{2}},
+		{code: "FRA", want: nil},
+	}
+	for _, tt := range tests {
+		filtered := sres.FilterByCountry(tt.code)
+		var got []uint64
+		for _, res := range filtered.Results {
+			got = append(got, res.TrackId)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: trackIds=%v want %v", tt.code, got, tt.want)
+		}
+		if g, w := filtered.ResultCount, uint64(len(tt.want)); g != w {
+			t.Errorf("%q: ResultCount=%d want %d", tt.code, g, w)
+		}
+	}
+}
+
+func TestSearchResultSortBy(t *testing.T) {
+	blob := []byte(`{
+		"resultCount": 3,
+		"results": [
+			{"trackId": 1, "trackName": "Banana Pancakes", "trackPrice": 1.29, "trackNumber": 3, "releaseDate": "2005-03-01T08:00:00Z"},
+			{"trackId": 2, "trackName": "Upside Down", "trackPrice": 0.99, "trackNumber": 1, "releaseDate": "2006-02-07T08:00:00Z"},
+			{"trackId": 3, "trackName": "Better Together", "trackPrice": 1.99, "trackNumber": 2, "releaseDate": "2004-11-15T08:00:00Z"}
+		]
+	}`)
+
+	tests := []struct {
+		field SortField
+		asc   bool
+		want  []uint64
+	}{
+		{field: SortByTrackName, asc: true, want: []uint64{1, 3, 2}},
+		{field: SortByTrackName, asc: false, want: []uint64{2, 3, 1}},
+		{field: SortByTrackPrice, asc: true, want: []uint64{2, 1, 3}},
+		{field: SortByTrackPrice, asc: false, want: []uint64{3, 1, 2}},
+		{field: SortByReleaseDate, asc: true, want: []uint64{3, 1, 2}},
+		{field: SortByReleaseDate, asc: false, want: []uint64{2, 1, 3}},
+		{field: SortByTrackNumber, asc: true, want: []uint64{2, 3, 1}},
+		{field: SortByTrackNumber, asc: false, want: []uint64{1, 3, 2}},
+	}
+	for _, tt := range tests {
+		sres := new(SearchResult)
+		if err := json.Unmarshal(blob, sres); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		sres.SortBy(tt.field, tt.asc)
+		var got []uint64
+		for _, res := range sres.Results {
+			got = append(got, res.TrackId)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("field=%d asc=%t: trackIds=%v want %v", tt.field, tt.asc, got, tt.want)
+		}
+	}
+}
+
+func TestResultArtistSearch(t *testing.T) {
+	res := &Result{ArtistName: "Daft Punk", TrackName: "One More Time"}
+	s := res.ArtistSearch()
+	if g, w := s.Term, "Daft Punk"; g != w {
+		t.Errorf("Term=%q want %q", g, w)
+	}
+	if g, w := s.Attribute, AttributeArtistTerm; g != w {
+		t.Errorf("Attribute=%q want %q", g, w)
+	}
+}
+
+func TestSearchResultGroupByKind(t *testing.T) {
+	var sres SearchResult
+	blob := `{"resultCount": 5, "results": [
+		{"wrapperType": "track", "kind": "song", "trackId": 1},
+		{"wrapperType": "track", "kind": "feature-movie", "trackId": 2},
+		{"wrapperType": "software", "kind": "software", "trackId": 3},
+		{"wrapperType": "track", "kind": "song", "trackId": 4},
+		{"wrapperType": "artist", "artistId": 5}
+	]}`
+	if err := json.Unmarshal([]byte(blob), &sres); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	trackIDs := func(results []*Result) []uint64 {
+		var ids []uint64
+		for _, res := range results {
+			ids = append(ids, res.TrackId)
+		}
+		return ids
+	}
+	groups := sres.GroupByKind()
+	if g, w := len(groups), 4; g != w {
+		t.Errorf("len(groups)=%d want %d", g, w)
+	}
+	if g, w := trackIDs(groups[string(KindSong)]), []uint64{1, 4}; !reflect.DeepEqual(g, w) {
+		t.Errorf("songs=%v want %v", g, w)
+	}
+	if g, w := trackIDs(groups[string(KindFeatureMovie)]), []uint64{2}; !reflect.DeepEqual(g, w) {
+		t.Errorf("movies=%v want %v", g, w)
+	}
+	if g, w := trackIDs(groups[string(KindSoftware)]), []uint64{3}; !reflect.DeepEqual(g, w) {
+		t.Errorf("software=%v want %v", g, w)
+	}
+	if g, w := len(groups[string(WrapperArtist)]), 1; g != w {
+		t.Errorf("len(artists)=%d want %d", g, w)
+	}
+}
+
+func TestResultGenrePairs(t *testing.T) {
+	var res Result
+	blob := `{
+		"kind": "podcast",
+		"genreIds": ["1318", "26", "1489"],
+		"genres": ["Technology", "Podcasts", "News"]
+	}`
+	if err := json.Unmarshal([]byte(blob), &res); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := []Genre{
+		{ID: "1318", Name: "Technology"},
+		{ID: "26", Name: "Podcasts"},
+		{ID: "1489", Name: "News"},
+	}
+	if g := res.GenrePairs(); !reflect.DeepEqual(g, want) {
+		t.Errorf("GenrePairs=%+v want %+v", g, want)
+	}
+
+	// Unaligned lists are padded rather than truncated.
+	res = Result{GenreIDs: []string{"1318", "26"}, Genres: []string{"Technology"}}
+	want = []Genre{{ID: "1318", Name: "Technology"}, {ID: "26"}}
+	if g := res.GenrePairs(); !reflect.DeepEqual(g, want) {
+		t.Errorf("GenrePairs=%+v want %+v", g, want)
+	}
+}
+
+func TestResultCollectionShareURL(t *testing.T) {
+	tests := [...]struct {
+		res  Result
+		want string
+	}{
+		{
+			res: Result{
+				CollectionId:      1440857781,
+				Country:           "USA",
+				CollectionViewURL: "https://music.apple.com/us/album/discovery/697194953",
+			},
+			want: "https://music.apple.com/us/album/discovery/697194953",
+		},
+		{res: Result{CollectionId: 1440857781, Country: "GB"}, want: "https://music.apple.com/gb/album/1440857781"},
+		{res: Result{CollectionId: 1440857781, Country: "USA"}, want: "https://music.apple.com/album/1440857781"},
+		{res: Result{TrackId: 1}, want: ""},
+	}
+	for i, tt := range tests {
+		if g, w := tt.res.CollectionShareURL(), tt.want; g != w {
+			t.Errorf("#%d: CollectionShareURL=%q want %q", i, g, w)
+		}
+	}
+}
+
+func TestResultAdvisoryRating(t *testing.T) {
+	var res Result
+	blob := `{
+		"wrapperType": "track",
+		"kind": "feature-movie",
+		"trackName": "Spider-Man: Into the Spider-Verse",
+		"contentAdvisoryRating": "PG"
+	}`
+	if err := json.Unmarshal([]byte(blob), &res); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if g, w := res.AdvisoryRating(), "PG"; g != w {
+		t.Errorf("AdvisoryRating=%q want %q", g, w)
+	}
+	if g := new(Result).AdvisoryRating(); g != "" {
+		t.Errorf("AdvisoryRating=%q want it empty", g)
+	}
+}
+
+func TestResultDisplayCollectionName(t *testing.T) {
+	var res Result
+	blob := `{
+		"collectionName": "good kid, m.A.A.d city",
+		"collectionCensoredName": "good kid, m.A.A.d city (Clean)"
+	}`
+	if err := json.Unmarshal([]byte(blob), &res); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if g, w := res.DisplayCollectionName(true), "good kid, m.A.A.d city (Clean)"; g != w {
+		t.Errorf("censored=%q want %q", g, w)
+	}
+	if g, w := res.DisplayCollectionName(false), "good kid, m.A.A.d city"; g != w {
+		t.Errorf("uncensored=%q want %q", g, w)
+	}
+
+	// Without a censored name, the collection name is used either way.
+	res.CollectionCensoredName = ""
+	if g, w := res.DisplayCollectionName(true), "good kid, m.A.A.d city"; g != w {
+		t.Errorf("censored=%q want %q", g, w)
+	}
+}
+
+func TestSearchResultTotalDuration(t *testing.T) {
+	sres := &SearchResult{Results: []*Result{
+		{TrackName: "One More Time", TrackTimeMillis: 320357},
+		{TrackName: "Aerodynamic", TrackTimeMillis: 212546},
+		{CollectionName: "Discovery"},
+		{TrackName: "Digital Love", TrackTimeMillis: 301373},
+	}}
+	want := 834276 * time.Millisecond
+	if g := sres.TotalDuration(); g != want {
+		t.Errorf("TotalDuration=%v want %v", g, want)
+	}
+	if g := new(SearchResult).TotalDuration(); g != 0 {
+		t.Errorf("no results: TotalDuration=%v want 0", g)
+	}
+}
+
+func TestSearchResultFilterByPrice(t *testing.T) {
+	sres := &SearchResult{Results: []*Result{
+		{TrackName: "Free", TrackPrice: 0},
+		{TrackName: "Cheap", TrackPrice: 0.99},
+		{TrackName: "Regular", TrackPrice: 1.29},
+		{TrackName: "Album Only", TrackPrice: -1},
+		{TrackName: "Movie", TrackPrice: 14.99},
+	}}
+	names := func(sr *SearchResult) []string {
+		var names []string
+		for _, res := range sr.Results {
+			names = append(names, res.TrackName)
+		}
+		return names
+	}
+
+	tests := [...]struct {
+		min, max float64
+		want     []string
+	}{
+		{0, 1.29, []string{"Free", "Cheap", "Regular"}},
+		{0.99, 1.29, []string{"Cheap", "Regular"}},
+		{1.29, 1.29, []string{"Regular"}},
+		{20, 30, nil},
+	}
+	for _, tt := range tests {
+		filtered := sres.FilterByPrice(tt.min, tt.max)
+		if g, w := names(filtered), tt.want; !reflect.DeepEqual(g, w) {
+			t.Errorf("FilterByPrice(%v, %v)=%q want %q", tt.min, tt.max, g, w)
+		}
+		if g, w := filtered.ResultCount, uint64(len(tt.want)); g != w {
+			t.Errorf("FilterByPrice(%v, %v): ResultCount=%d want %d", tt.min, tt.max, g, w)
+		}
+	}
+}
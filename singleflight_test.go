@@ -0,0 +1,127 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithSingleflight(t *testing.T) {
+	var hits int32
+	arrived := make(chan bool, 1)
+	release := make(chan bool)
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		select {
+		case arrived <- true:
+		default:
+		}
+		<-release
+		fmt.Fprint(w, `{"resultCount": 1, "results": [{"trackId": 1}]}`)
+	}))
+	defer cst.Close()
+
+	client := NewClient(WithSingleflight())
+	client.searchURL = cst.URL
+
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	results := make([]*SearchResult, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sres, err := client.Search(context.Background(), &Search{Term: "herd"})
+			if err != nil {
+				errs <- err
+				return
+			}
+			results[i] = sres
+		}(i)
+	}
+
+	// Hold the one request at the backend until the other searches
+	// have had time to pile up behind it.
+	<-arrived
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Search: %v", err)
+	}
+	if g, w := atomic.LoadInt32(&hits), int32(1); g != w {
+		t.Errorf("backend got %d requests want %d", g, w)
+	}
+	// Each caller gets its own copy of the results.
+	if results[0] != nil && results[0] == results[1] {
+		t.Error("callers share the same *SearchResult")
+	}
+}
+
+func TestWithSingleflightDistinctHeaders(t *testing.T) {
+	type requestIDKey struct{}
+	arrived := make(chan string, 2)
+	release := make(chan bool)
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- r.Header.Get("X-Request-ID")
+		<-release
+		fmt.Fprint(w, `{"resultCount": 1, "results": [{"trackId": 1}]}`)
+	}))
+	defer cst.Close()
+	defer close(release)
+
+	client := NewClient(WithSingleflight(), WithRequestIDFromContext(requestIDKey{}))
+	client.searchURL = cst.URL
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			ctx := context.WithValue(context.Background(), requestIDKey{}, id)
+			if _, err := client.Search(ctx, &Search{Term: "herd"}); err != nil {
+				t.Errorf("%s: Search: %v", id, err)
+			}
+		}(id)
+	}
+
+	// Searches with different request ids must not be collapsed,
+	// so both reach the backend while the first is held there.
+	ids := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		select {
+		case id := <-arrived:
+			ids[id] = true
+		case <-time.After(2 * time.Second):
+			t.Fatalf("only %d of 2 requests reached the backend", i)
+		}
+	}
+	if !ids["a"] || !ids["b"] {
+		t.Errorf("request ids=%v want a and b", ids)
+	}
+	release <- true
+	release <- true
+	wg.Wait()
+}
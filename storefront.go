@@ -0,0 +1,76 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import "strings"
+
+// storefrontIDs maps two-letter country codes to the
+// ids of their iTunes Store storefronts.
+var storefrontIDs = map[Country]string{
+	"US": "143441",
+	"FR": "143442",
+	"DE": "143443",
+	"GB": "143444",
+	"AT": "143445",
+	"BE": "143446",
+	"FI": "143447",
+	"GR": "143448",
+	"IE": "143449",
+	"IT": "143450",
+	"LU": "143451",
+	"NL": "143452",
+	"PT": "143453",
+	"ES": "143454",
+	"CA": "143455",
+	"SE": "143456",
+	"NO": "143457",
+	"DK": "143458",
+	"CH": "143459",
+	"AU": "143460",
+	"NZ": "143461",
+	"JP": "143462",
+	"HK": "143463",
+	"SG": "143464",
+	"CN": "143465",
+	"KR": "143466",
+	"IN": "143467",
+	"MX": "143468",
+	"RU": "143469",
+	"TW": "143470",
+	"VN": "143471",
+	"ZA": "143472",
+	"MY": "143473",
+	"PH": "143474",
+	"TH": "143475",
+	"ID": "143476",
+	"PK": "143477",
+	"PL": "143478",
+	"SA": "143479",
+	"TR": "143480",
+	"AE": "143481",
+	"HU": "143482",
+	"CL": "143483",
+	"BR": "143503",
+	"AR": "143505",
+}
+
+// StorefrontID returns the id of the iTunes Store storefront of the
+// country with the two-letter code country, e.g. "143441" for "US",
+// as used in the X-Apple-Store-Front header, see WithStorefront. It
+// reports false for countries that it doesn't know of.
+func StorefrontID(country Country) (string, bool) {
+	id, ok := storefrontIDs[Country(strings.ToUpper(string(country)))]
+	return id, ok
+}
@@ -0,0 +1,38 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import "testing"
+
+func TestStorefrontID(t *testing.T) {
+	tests := [...]struct {
+		country Country
+		want    string
+		wantOK  bool
+	}{
+		{"US", "143441", true},
+		{"GB", "143444", true},
+		{"JP", "143462", true},
+		{"de", "143443", true},
+		{"XX", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		id, ok := StorefrontID(tt.country)
+		if id != tt.want || ok != tt.wantOK {
+			t.Errorf("StorefrontID(%q)=(%q, %t) want (%q, %t)", tt.country, id, ok, tt.want, tt.wantOK)
+		}
+	}
+}
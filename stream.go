@@ -0,0 +1,145 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// SearchStream is like Search but, instead of buffering the whole
+// response, decodes its results one at a time and calls fn with each
+// of them in order. It stops at the first error returned by fn, or
+// when ctx is done, and returns that error. Streamed searches bypass
+// the client's cache.
+func (c *Client) SearchStream(ctx context.Context, s *Search, fn func(*Result) error) error {
+	s, err := c.prepareSearch(s)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.withDefaultTimeout(ctx)
+	defer cancel()
+
+	rawURL, err := c.requestURL(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.do(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if !statusOK(res.StatusCode) {
+		return responseError(res)
+	}
+	br := bufio.NewReader(res.Body)
+	if looksLikeHTML(res, br) {
+		blob, _ := io.ReadAll(io.LimitReader(br, maxErrorBodyBytes))
+		return htmlError(res, blob)
+	}
+	var body io.Reader = br
+	if c.jsonpCallback != "" {
+		body = skipJSONPPrefix(body, c.jsonpCallback)
+	}
+	return c.decodeStream(ctx, body, fn)
+}
+
+// decodeStream decodes the results in r one at a time, skipping
+// every other field of the response. An empty body has no results.
+func (c *Client) decodeStream(ctx context.Context, r io.Reader, fn func(*Result) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return &DecodeError{Err: err}
+		}
+		if key, _ := tok.(string); key != "results" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return &DecodeError{Err: err}
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			res := new(Result)
+			if err := dec.Decode(res); err != nil {
+				return &DecodeError{Err: err}
+			}
+			c.normalize(res)
+			if err := fn(res); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// looksLikeHTML reports whether res is an HTML page rather than JSON,
+// by its Content-Type or else, like htmlError, by a leading '<' in its
+// body, which it reads from br past any leading whitespace.
+func looksLikeHTML(res *http.Response, br *bufio.Reader) bool {
+	if strings.Contains(res.Header.Get("Content-Type"), "html") {
+		return true
+	}
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return false
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		br.UnreadByte()
+		return b == '<'
+	}
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return &DecodeError{Err: err}
+	}
+	if got, ok := tok.(json.Delim); !ok || got != want {
+		return &DecodeError{Err: fmt.Errorf("unexpected %v in response, want %q", tok, want)}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSearchStream(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultCount": 3, "results": [
+			{"trackId": 1, "trackName": "One"},
+			{"trackId": 2, "trackName": "Two"},
+			{"trackId": 3, "trackName": "Three"}
+		]}`)
+	}))
+	defer cst.Close()
+
+	client := &Client{searchURL: cst.URL}
+	var names []string
+	err := client.SearchStream(context.Background(), &Search{Term: "numbers"}, func(res *Result) error {
+		names = append(names, res.TrackName)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SearchStream: %v", err)
+	}
+	if g, w := names, []string{"One", "Two", "Three"}; !reflect.DeepEqual(g, w) {
+		t.Errorf("names=%q want %q", g, w)
+	}
+
+	// An error from the callback stops the stream.
+	errStop := errors.New("stop")
+	calls := 0
+	err = client.SearchStream(context.Background(), &Search{Term: "numbers"}, func(res *Result) error {
+		calls++
+		return errStop
+	})
+	if err != errStop {
+		t.Errorf("err=%v want %v", err, errStop)
+	}
+	if g, w := calls, 1; g != w {
+		t.Errorf("calls=%d want %d", g, w)
+	}
+}
+
+func TestSearchStreamEmptyBody(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer cst.Close()
+
+	client := &Client{searchURL: cst.URL}
+	err := client.SearchStream(context.Background(), &Search{Term: "nothing"}, func(res *Result) error {
+		t.Errorf("unexpected result %+v", res)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("SearchStream: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+
+	"go.opencensus.io/trace"
+)
+
+type tracingDisabledKey struct{}
+
+// withoutTracing marks ctx so that startSpan creates no spans under it.
+func withoutTracing(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tracingDisabledKey{}, true)
+}
+
+// traceContext returns ctx as the client traces under it:
+// marked withoutTracing if the client's tracing is off.
+func (c *Client) traceContext(ctx context.Context) context.Context {
+	if c.disableTracing {
+		return withoutTracing(ctx)
+	}
+	return ctx
+}
+
+// startSpan is trace.StartSpan, unless tracing was disabled for
+// ctx in which case it returns ctx and a nil span, which is safe
+// to end and annotate.
+func startSpan(ctx context.Context, name string) (context.Context, *trace.Span) {
+	if disabled, _ := ctx.Value(tracingDisabledKey{}).(bool); disabled {
+		return ctx, nil
+	}
+	return trace.StartSpan(ctx, name)
+}
+
+// searchAttributes describes s for its span. Only the parameters
+// that shape the search are included, never headers or Extra.
+func searchAttributes(s *Search) []trace.Attribute {
+	return []trace.Attribute{
+		trace.StringAttribute("itunes.term", s.term()),
+		trace.StringAttribute("itunes.media", string(s.Media)),
+		trace.StringAttribute("itunes.entity", s.entityList()),
+		trace.StringAttribute("itunes.country", string(s.Country)),
+		trace.Int64Attribute("itunes.limit", int64(s.Limit)),
+	}
+}
+
+// annotateSpan records the outcome of a search on span.
+func annotateSpan(span *trace.Span, sres *SearchResult, err error) {
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+		return
+	}
+	span.AddAttributes(trace.Int64Attribute("itunes.result_count", int64(len(sres.Results))))
+}
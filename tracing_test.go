@@ -0,0 +1,138 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"go.opencensus.io/trace"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (sr *spanRecorder) ExportSpan(sd *trace.SpanData) {
+	sr.mu.Lock()
+	sr.spans = append(sr.spans, sd)
+	sr.mu.Unlock()
+}
+
+func (sr *spanRecorder) reset() []*trace.SpanData {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	spans := sr.spans
+	sr.spans = nil
+	return spans
+}
+
+func TestWithTracing(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultCount": 0, "results": []}`)
+	}))
+	defer cst.Close()
+
+	recorder := new(spanRecorder)
+	trace.RegisterExporter(recorder)
+	defer trace.UnregisterExporter(recorder)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	defer trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(1e-4)})
+
+	ctx := context.Background()
+	traced := &Client{searchURL: cst.URL}
+	if _, err := traced.Search(ctx, &Search{Term: "traced"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if spans := recorder.reset(); len(spans) == 0 {
+		t.Error("expected spans with tracing on by default")
+	}
+
+	untraced := NewClient(WithTracing(false))
+	untraced.searchURL = cst.URL
+	s := &Search{Term: "untraced"}
+	calls := map[string]func() error{
+		"Search": func() error {
+			_, err := untraced.Search(ctx, s)
+			return err
+		},
+		"SearchStream": func() error {
+			return untraced.SearchStream(ctx, s, func(*Result) error { return nil })
+		},
+		"SearchInto": func() error {
+			var out json.RawMessage
+			return untraced.SearchInto(ctx, s, &out)
+		},
+		"BuildRequest": func() error {
+			_, err := untraced.BuildRequest(ctx, s)
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, sd := range recorder.reset() {
+			t.Errorf("%s: unexpected span %q with tracing off", name, sd.Name)
+		}
+	}
+}
+
+func TestSearchSpanAttributes(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultCount": 2, "results": [{"trackId": 1}, {"trackId": 2}]}`)
+	}))
+	defer cst.Close()
+
+	recorder := new(spanRecorder)
+	trace.RegisterExporter(recorder)
+	defer trace.UnregisterExporter(recorder)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	defer trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(1e-4)})
+
+	client := &Client{searchURL: cst.URL}
+	s := &Search{Term: "jack johnson", Media: MediaMusic, Entity: EntityMusicVideo, Country: "US", Limit: 25}
+	if _, err := client.Search(context.Background(), s); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	var searchSpan *trace.SpanData
+	for _, sd := range recorder.reset() {
+		if sd.Name == "itunes.(*Client).Search" {
+			searchSpan = sd
+		}
+	}
+	if searchSpan == nil {
+		t.Fatal("no span for the search")
+	}
+	want := map[string]interface{}{
+		"itunes.term":         "jack johnson",
+		"itunes.media":        "music",
+		"itunes.entity":       "musicVideo",
+		"itunes.country":      "US",
+		"itunes.limit":        int64(25),
+		"itunes.result_count": int64(2),
+	}
+	if !reflect.DeepEqual(searchSpan.Attributes, want) {
+		t.Errorf("attributes=%v\nwant %v", searchSpan.Attributes, want)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"net/url"
+	"strings"
+)
+
+// storeBaseURL is what relative URLs in results are resolved against.
+var storeBaseURL = &url.URL{Scheme: "https", Host: "itunes.apple.com", Path: "/"}
+
+// absoluteURL makes rawURL an absolute https URL: protocol-relative
+// URLs get the https scheme, http is upgraded to https and relative
+// URLs are resolved against the iTunes store. Empty and unparseable
+// URLs are returned as they are.
+func absoluteURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return rawURL
+	}
+	switch {
+	case u.Host == "":
+		u = storeBaseURL.ResolveReference(u)
+	case u.Scheme == "" || u.Scheme == "http":
+		u.Scheme = "https"
+	}
+	return u.String()
+}
+
+// normalizeURLs makes all of the result's URLs absolute https URLs.
+func (r *Result) normalizeURLs() {
+	for _, field := range []*string{
+		&r.TrackViewURL,
+		&r.CollectionViewURL,
+		&r.ArtistViewURL,
+		&r.PreviewURL,
+		&r.FeedURL,
+		&r.ArtworkURL100Px,
+		&r.ArtworkURL60Px,
+		&r.ArtworkURL30Px,
+	} {
+		*field = absoluteURL(*field)
+	}
+}
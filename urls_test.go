@@ -0,0 +1,59 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithNormalizeURLs(t *testing.T) {
+	cst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resultCount": 1, "results": [{
+			"trackId": 1,
+			"artworkUrl100": "//is1-ssl.mzstatic.com/image/thumb/source/100x100bb.jpg",
+			"previewUrl": "http://audio-ssl.itunes.apple.com/preview.m4a",
+			"trackViewUrl": "/us/album/one-more-time/697194953?i=697195787",
+			"artistViewUrl": "https://music.apple.com/us/artist/daft-punk/5468295"
+		}]}`)
+	}))
+	defer cst.Close()
+
+	client := NewClient(WithNormalizeURLs())
+	client.searchURL = cst.URL
+	sres, err := client.Search(context.Background(), &Search{Term: "daft punk"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	res := sres.Results[0]
+	tests := [...]struct {
+		name, got, want string
+	}{
+		{"ArtworkURL100Px", res.ArtworkURL100Px, "https://is1-ssl.mzstatic.com/image/thumb/source/100x100bb.jpg"},
+		{"PreviewURL", res.PreviewURL, "https://audio-ssl.itunes.apple.com/preview.m4a"},
+		{"TrackViewURL", res.TrackViewURL, "https://itunes.apple.com/us/album/one-more-time/697194953?i=697195787"},
+		{"ArtistViewURL", res.ArtistViewURL, "https://music.apple.com/us/artist/daft-punk/5468295"},
+		{"CollectionViewURL", res.CollectionViewURL, ""},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s=%q want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	MediaMovie      Media = "movie"
+	MediaPodcast    Media = "podcast"
+	MediaMusic      Media = "music"
+	MediaMusicVideo Media = "musicVideo"
+	MediaAudioBook  Media = "audiobook"
+	MediaShortFilm  Media = "shortFilm"
+	MediaTVShow     Media = "tvShow"
+	MediaSoftware   Media = "software"
+	MediaEBook      Media = "ebook"
+	MediaAll        Media = "all"
+)
+
+// entitiesByMedia lists the entities that the API accepts for each media.
+var entitiesByMedia = map[Media][]Entity{
+	MediaMovie:      {EntityMovieArtist, EntityMovie},
+	MediaPodcast:    {EntityPodcastAuthor, EntityPodcast},
+	MediaMusic:      {EntityMusicArtist, EntityMusicTrack, EntityAlbum, EntityMusicVideo, EntityMix, EntitySong, EntityRingtone},
+	MediaMusicVideo: {EntityMusicArtist, EntityMusicVideo},
+	MediaAudioBook:  {EntityAudioBookAuthor, EntityAudioBook},
+	MediaShortFilm:  {EntityShortFilmArtist, EntityShortFilm},
+	MediaTVShow:     {EntityTVEpisode, EntityTVSeason},
+	MediaSoftware:   {EntitySoftware, EntityIPadSoftware, EntityMacSoftware},
+	MediaEBook:      {EntityEBook},
+	MediaAll:        {EntityMovie, EntityAlbum, EntityAllArtist, EntityPodcast, EntityMusicVideo, EntityMix, EntityAudioBook, EntityTVSeason, EntityAllTrack},
+}
+
+// otherEntities are the entities that aren't specific to any media.
+var otherEntities = []Entity{EntityMusic, EntityTVShow, EntityAll, EntityRingtone}
+
+// canonicalMedia and canonicalEntities map the lowercased
+// tokens of the known media and entities to their casing.
+var canonicalMedia, canonicalEntities = func() (map[string]Media, map[string]Entity) {
+	media := make(map[string]Media)
+	entities := make(map[string]Entity)
+	for m, mediaEntities := range entitiesByMedia {
+		media[strings.ToLower(string(m))] = m
+		for _, entity := range mediaEntities {
+			entities[strings.ToLower(string(entity))] = entity
+		}
+	}
+	for _, entity := range otherEntities {
+		entities[strings.ToLower(string(entity))] = entity
+	}
+	return media, entities
+}()
+
+// withCanonicalTokens returns a copy of s with its known media and
+// entities spelled in their canonical casing, e.g. "musicVideo" for
+// "MusicVideo". Unknown tokens are kept as they are.
+func (s *Search) withCanonicalTokens() *Search {
+	s = s.Clone()
+	if media, ok := canonicalMedia[strings.ToLower(string(s.Media))]; ok {
+		s.Media = media
+	}
+	canonical := func(entity Entity) Entity {
+		if known, ok := canonicalEntities[strings.ToLower(string(entity))]; ok {
+			return known
+		}
+		return entity
+	}
+	s.Entity = canonical(s.Entity)
+	for i, entity := range s.Entities {
+		s.Entities[i] = canonical(entity)
+	}
+	return s
+}
+
+// Validate reports whether s can be sent as a search: it must have a
+// Term, Terms or an Id, a valid Version if any and, for the media types
+// known to this package, each of its entities must be one the API
+// accepts for its media. An empty media or entity defers to the API's
+// defaults.
+func (s *Search) Validate() error {
+	if s.Id == "" && s.term() == "" {
+		return ErrMissingTerm
+	}
+	switch s.Version {
+	case "", Version1, Version2:
+	default:
+		return fmt.Errorf("invalid version %q, want %q or %q", s.Version, Version1, Version2)
+	}
+	entityList := s.entityList()
+	if s.Media == "" || entityList == "" {
+		return nil
+	}
+	entities, known := entitiesByMedia[s.Media]
+	if !known {
+		return nil
+	}
+
+	for _, entity := range strings.Split(entityList, ",") {
+		if !containsEntity(entities, Entity(entity)) {
+			return fmt.Errorf("entity %q is not valid for media %q", entity, s.Media)
+		}
+	}
+	return nil
+}
+
+func containsEntity(entities []Entity, entity Entity) bool {
+	for _, e := range entities {
+		if e == entity {
+			return true
+		}
+	}
+	return false
+}
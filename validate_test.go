@@ -0,0 +1,147 @@
+// Copyright 2018 Orijtech, Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package itunes
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+func TestSearchValidate(t *testing.T) {
+	tests := []struct {
+		media   Media
+		entity  Entity
+		wantErr bool
+	}{
+		{media: MediaMovie, entity: EntityMovieArtist},
+		{media: MediaMovie, entity: EntityMovie},
+		{media: MediaMusic, entity: EntityMusicTrack},
+		{media: MediaMusic, entity: "album,musicVideo"},
+		{media: MediaSoftware, entity: EntityIPadSoftware},
+		{media: MediaTVShow, entity: EntityTVSeason},
+		{media: MediaAll, entity: EntityAllTrack},
+		{media: MediaMusic},
+		{entity: EntityMovieArtist},
+
+		{media: MediaMusic, entity: EntityMovieArtist, wantErr: true},
+		{media: MediaPodcast, entity: EntityTVEpisode, wantErr: true},
+		{media: MediaEBook, entity: EntityAudioBook, wantErr: true},
+		{media: MediaMusicVideo, entity: "musicArtist,album", wantErr: true},
+		{media: MediaSoftware, entity: EntityMovie, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		s := &Search{Term: "x", Media: tt.media, Entity: tt.entity}
+		err := s.Validate()
+		if tt.wantErr && err == nil {
+			t.Errorf("media=%q entity=%q: expected an error", tt.media, tt.entity)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("media=%q entity=%q: unexpected error: %v", tt.media, tt.entity, err)
+		}
+	}
+
+	if err := new(Search).Validate(); err != ErrMissingTerm {
+		t.Errorf("empty search: err=%v want %v", err, ErrMissingTerm)
+	}
+}
+
+func TestSearchVersion(t *testing.T) {
+	for _, version := range []Version{"3", "v2", "2.0"} {
+		if err := (&Search{Term: "x", Version: version}).Validate(); err == nil {
+			t.Errorf("version=%q: expected an error", version)
+		}
+	}
+
+	ctx := context.Background()
+	for _, version := range []Version{Version1, Version2} {
+		s := &Search{Term: "x", Version: version}
+		if err := s.Validate(); err != nil {
+			t.Errorf("version=%q: unexpected error: %v", version, err)
+		}
+		values, err := valueToURLValues(ctx, s)
+		if err != nil {
+			t.Fatalf("valueToURLValues: %v", err)
+		}
+		if g, w := values.Get("version"), string(version); g != w {
+			t.Errorf("version=%q want %q", g, w)
+		}
+	}
+}
+
+func TestEntityTokens(t *testing.T) {
+	tests := [...]struct {
+		entity Entity
+		media  Media
+		want   string
+	}{
+		{EntityMusicTrack, MediaMusic, "musicTrack"},
+		{EntityAlbum, MediaMusic, "album"},
+		{EntityMix, MediaMusic, "mix"},
+		{EntitySong, MediaMusic, "song"},
+		{EntityRingtone, MediaMusic, "ringtone"},
+		{EntityAllArtist, MediaAll, "allArtist"},
+	}
+	for _, tt := range tests {
+		s := &Search{Term: "x", Media: tt.media, Entity: tt.entity}
+		if err := s.Validate(); err != nil {
+			t.Errorf("entity=%q: Validate: %v", tt.entity, err)
+		}
+		query, err := s.EncodeQuery(context.Background())
+		if err != nil {
+			t.Fatalf("entity=%q: EncodeQuery: %v", tt.entity, err)
+		}
+		values, _ := url.ParseQuery(query)
+		if g, w := values.Get("entity"), tt.want; g != w {
+			t.Errorf("entity=%q want %q", g, w)
+		}
+	}
+}
+
+func TestWithNormalizeTokens(t *testing.T) {
+	s := &Search{
+		Term:     "x",
+		Media:    "Music",
+		Entity:   "MUSICVIDEO",
+		Entities: []Entity{"musictrack"},
+	}
+	ctx := context.Background()
+
+	client := NewClient(WithNormalizeTokens())
+	req, err := client.BuildRequest(ctx, s)
+	if err != nil {
+		t.Fatalf("BuildRequest: %v", err)
+	}
+	query := req.URL.Query()
+	if g, w := query.Get("media"), "music"; g != w {
+		t.Errorf("media=%q want %q", g, w)
+	}
+	if g, w := query.Get("entity"), "musicVideo,musicTrack"; g != w {
+		t.Errorf("entity=%q want %q", g, w)
+	}
+	// The caller's Search is left as it was.
+	if g, w := s.Media, Media("Music"); g != w {
+		t.Errorf("Search.Media=%q want %q", g, w)
+	}
+
+	req, err = new(Client).BuildRequest(ctx, s)
+	if err != nil {
+		t.Fatalf("BuildRequest: %v", err)
+	}
+	if g, w := req.URL.Query().Get("media"), "Music"; g != w {
+		t.Errorf("without the option: media=%q want %q", g, w)
+	}
+}